@@ -0,0 +1,248 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/puller"
+)
+
+// Handler is a function type which handles new chat events.
+// If a handler returns an error, the event is passed to all handlers again on the next poll.
+type Handler func(ctx context.Context, event *Event) error
+
+// Auditor polls event log of a supergroup or channel and passes new events to handlers in chronological order.
+// Administrator rights are required.
+type Auditor struct {
+	tdlibClient *client.Client
+	mu          sync.Mutex
+	pollMu      sync.Mutex
+	chatID      int64
+	interval    time.Duration
+	filters     *client.ChatEventLogFilters
+	userIDs     []int32
+	handlers    []Handler
+	store       puller.Store
+	key         string
+	lastEventID int64
+	initialized bool
+}
+
+// Option is a function type which adjusts auditor's configuration.
+type Option func(*Auditor)
+
+// WithInterval configures the auditor to poll event log with specified interval.
+func WithInterval(interval time.Duration) Option {
+	return func(auditor *Auditor) {
+		if interval > 0 {
+			auditor.interval = interval
+		}
+	}
+}
+
+// WithFilters configures the auditor to poll only events of specified types.
+func WithFilters(filters *client.ChatEventLogFilters) Option {
+	return func(auditor *Auditor) {
+		auditor.filters = filters
+	}
+}
+
+// WithUserIDs configures the auditor to poll only events of specified users.
+func WithUserIDs(userIDs ...int32) Option {
+	return func(auditor *Auditor) {
+		auditor.userIDs = userIDs
+	}
+}
+
+// WithStore configures the auditor to persist identifier of the last handled event in the store by the key,
+// so events happened while the auditor was stopped are handled after restart.
+func WithStore(store puller.Store, key string) Option {
+	return func(auditor *Auditor) {
+		auditor.store = store
+		auditor.key = key
+	}
+}
+
+// New creates new auditor of the chat event log.
+func New(tdlibClient *client.Client, chatID int64, options ...Option) *Auditor {
+	auditor := &Auditor{
+		tdlibClient: tdlibClient,
+		chatID:      chatID,
+		interval:    30 * time.Second,
+	}
+
+	for _, option := range options {
+		option(auditor)
+	}
+
+	return auditor
+}
+
+// Handle registers the handler of new events.
+func (auditor *Auditor) Handle(handler Handler) {
+	auditor.mu.Lock()
+	defer auditor.mu.Unlock()
+
+	auditor.handlers = append(auditor.handlers, handler)
+}
+
+// Run polls event log with the configured interval until the context is done.
+// Events happened before the first poll are skipped unless the last handled event is persisted in the store.
+func (auditor *Auditor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(auditor.interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := auditor.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("chat %d event log error: %s\n", auditor.chatID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches events happened since the previous poll and passes them to handlers.
+// The first poll only remembers the newest event unless the last handled event is persisted in the store.
+// Polling stops at the first event which a handler fails on; the handled events and the error are returned,
+// and the failed event is handled again on the next poll.
+func (auditor *Auditor) Poll(ctx context.Context) ([]*Event, error) {
+	auditor.pollMu.Lock()
+	defer auditor.pollMu.Unlock()
+
+	if !auditor.initialized {
+		if auditor.store != nil {
+			ok, err := auditor.store.Load(auditor.key, &auditor.lastEventID)
+			if err != nil {
+				return nil, err
+			}
+			auditor.initialized = ok
+		}
+	}
+
+	events, err := auditor.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auditor.initialized {
+		auditor.initialized = true
+		if len(events) > 0 {
+			auditor.lastEventID = events[len(events)-1].ID
+		}
+
+		return nil, auditor.save()
+	}
+
+	auditor.mu.Lock()
+	handlers := make([]Handler, len(auditor.handlers))
+	copy(handlers, auditor.handlers)
+	auditor.mu.Unlock()
+
+	for i, event := range events {
+		for _, handler := range handlers {
+			err := handler(ctx, event)
+			if err != nil {
+				saveErr := auditor.save()
+				if saveErr != nil {
+					log.Printf("chat %d event log save error: %s\n", auditor.chatID, saveErr)
+				}
+
+				return events[:i], fmt.Errorf("event %d handler: %s", event.ID, err)
+			}
+		}
+
+		auditor.lastEventID = event.ID
+	}
+
+	return events, auditor.save()
+}
+
+// fetch returns events newer than the last handled one in chronological order. Must be called under the poll lock.
+func (auditor *Auditor) fetch(ctx context.Context) ([]*Event, error) {
+	eventPuller := puller.ChatEventLog(ctx, auditor.tdlibClient, puller.EventLogCursor{
+		ChatID:  auditor.chatID,
+		Filters: auditor.filters,
+		UserIDs: auditor.userIDs,
+	})
+	defer eventPuller.Close()
+
+	var events []*Event
+	for {
+		chatEvent, ok := eventPuller.Next()
+		if !ok {
+			break
+		}
+
+		if auditor.initialized && int64(chatEvent.ID) <= auditor.lastEventID {
+			break
+		}
+
+		events = append(events, NewEvent(auditor.chatID, chatEvent))
+
+		// only the newest event is needed to initialize the auditor
+		if !auditor.initialized {
+			break
+		}
+	}
+
+	err := eventPuller.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// events are pulled from the newest to the oldest
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	return events, nil
+}
+
+func (auditor *Auditor) save() error {
+	if auditor.store == nil {
+		return nil
+	}
+
+	return auditor.store.Save(auditor.key, auditor.lastEventID)
+}
+
+// ToChat returns handler which sends description of every event as a text message to the chat.
+func ToChat(tdlibClient *client.Client, chatID int64) Handler {
+	return func(ctx context.Context, event *Event) error {
+		_, err := tdlibClient.SendMessage(&client.SendMessageRequest{
+			ChatID: chatID,
+			InputMessageContent: &client.InputMessageText{
+				Text: &client.FormattedText{
+					Text: event.String(),
+				},
+			},
+		})
+
+		return err
+	}
+}
+
+// ToWriter returns handler which writes every event to the writer as a JSON object on a separate line.
+func ToWriter(writer io.Writer) Handler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(writer)
+
+	return func(ctx context.Context, event *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return encoder.Encode(event)
+	}
+}
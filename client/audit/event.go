@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Kind is a kind of an audited chat event.
+type Kind string
+
+// Kinds of audited chat events.
+const (
+	KindMessageEdited                Kind = "message_edited"
+	KindMessageDeleted               Kind = "message_deleted"
+	KindMessagePinned                Kind = "message_pinned"
+	KindMessageUnpinned              Kind = "message_unpinned"
+	KindMemberJoined                 Kind = "member_joined"
+	KindMemberLeft                   Kind = "member_left"
+	KindMemberInvited                Kind = "member_invited"
+	KindMemberPromoted               Kind = "member_promoted"
+	KindMemberDemoted                Kind = "member_demoted"
+	KindMemberRestricted             Kind = "member_restricted"
+	KindMemberBanned                 Kind = "member_banned"
+	KindMemberUnbanned               Kind = "member_unbanned"
+	KindTitleChanged                 Kind = "title_changed"
+	KindDescriptionChanged           Kind = "description_changed"
+	KindUsernameChanged              Kind = "username_changed"
+	KindPhotoChanged                 Kind = "photo_changed"
+	KindInvitesToggled               Kind = "invites_toggled"
+	KindSignMessagesToggled          Kind = "sign_messages_toggled"
+	KindStickerSetChanged            Kind = "sticker_set_changed"
+	KindIsAllHistoryAvailableToggled Kind = "is_all_history_available_toggled"
+	KindUnknown                      Kind = "unknown"
+)
+
+// Event is a chat event of a supergroup or channel event log.
+type Event struct {
+	ChatID int64     `json:"chat_id"`
+	ID     int64     `json:"id"`
+	Date   time.Time `json:"date"`
+	// Identifier of the user who performed the action
+	UserID int32 `json:"user_id"`
+	Kind   Kind  `json:"kind"`
+	// Identifier of the user affected by the action; 0 if none
+	TargetUserID int32 `json:"target_user_id,omitempty"`
+	// Human readable description of the action
+	Description string `json:"description"`
+	// Original action reported by TDLib
+	Action client.ChatEventAction `json:"action"`
+}
+
+// String returns the event as a line suitable for logs.
+func (event *Event) String() string {
+	return fmt.Sprintf("%s user %d: %s", event.Date.Format(time.RFC3339), event.UserID, event.Description)
+}
+
+// NewEvent converts the chat event into the typed event.
+func NewEvent(chatID int64, chatEvent *client.ChatEvent) *Event {
+	event := &Event{
+		ChatID: chatID,
+		ID:     int64(chatEvent.ID),
+		Date:   time.Unix(int64(chatEvent.Date), 0).UTC(),
+		UserID: chatEvent.UserID,
+		Kind:   KindUnknown,
+		Action: chatEvent.Action,
+	}
+
+	switch action := chatEvent.Action.(type) {
+	case *client.ChatEventMessageEdited:
+		event.Kind = KindMessageEdited
+		event.Description = fmt.Sprintf("edited message %d", messageID(action.NewMessage))
+
+	case *client.ChatEventMessageDeleted:
+		event.Kind = KindMessageDeleted
+		event.Description = fmt.Sprintf("deleted message %d", messageID(action.Message))
+		if action.Message != nil {
+			event.TargetUserID = action.Message.SenderUserID
+		}
+
+	case *client.ChatEventMessagePinned:
+		event.Kind = KindMessagePinned
+		event.Description = fmt.Sprintf("pinned message %d", messageID(action.Message))
+
+	case *client.ChatEventMessageUnpinned:
+		event.Kind = KindMessageUnpinned
+		event.Description = "unpinned message"
+
+	case *client.ChatEventMemberJoined:
+		event.Kind = KindMemberJoined
+		event.Description = "joined the chat"
+
+	case *client.ChatEventMemberLeft:
+		event.Kind = KindMemberLeft
+		event.Description = "left the chat"
+
+	case *client.ChatEventMemberInvited:
+		event.Kind = KindMemberInvited
+		event.TargetUserID = action.UserID
+		event.Description = fmt.Sprintf("invited user %d", action.UserID)
+
+	case *client.ChatEventMemberPromoted:
+		event.Kind = KindMemberPromoted
+		if !isAdministrator(action.NewStatus) {
+			event.Kind = KindMemberDemoted
+		}
+		event.TargetUserID = action.UserID
+		event.Description = fmt.Sprintf("changed administrator rights of user %d: %s -> %s", action.UserID, statusName(action.OldStatus), statusName(action.NewStatus))
+
+	case *client.ChatEventMemberRestricted:
+		event.Kind = KindMemberRestricted
+		if _, ok := action.NewStatus.(*client.ChatMemberStatusBanned); ok {
+			event.Kind = KindMemberBanned
+		} else if _, ok := action.OldStatus.(*client.ChatMemberStatusBanned); ok {
+			event.Kind = KindMemberUnbanned
+		}
+		event.TargetUserID = action.UserID
+		event.Description = fmt.Sprintf("changed restrictions of user %d: %s -> %s", action.UserID, statusName(action.OldStatus), statusName(action.NewStatus))
+
+	case *client.ChatEventTitleChanged:
+		event.Kind = KindTitleChanged
+		event.Description = fmt.Sprintf("changed title from %q to %q", action.OldTitle, action.NewTitle)
+
+	case *client.ChatEventDescriptionChanged:
+		event.Kind = KindDescriptionChanged
+		event.Description = fmt.Sprintf("changed description from %q to %q", action.OldDescription, action.NewDescription)
+
+	case *client.ChatEventUsernameChanged:
+		event.Kind = KindUsernameChanged
+		event.Description = fmt.Sprintf("changed username from %q to %q", action.OldUsername, action.NewUsername)
+
+	case *client.ChatEventPhotoChanged:
+		event.Kind = KindPhotoChanged
+		event.Description = "changed chat photo"
+
+	case *client.ChatEventInvitesToggled:
+		event.Kind = KindInvitesToggled
+		event.Description = fmt.Sprintf("set anyone can invite to %t", action.AnyoneCanInvite)
+
+	case *client.ChatEventSignMessagesToggled:
+		event.Kind = KindSignMessagesToggled
+		event.Description = fmt.Sprintf("set sign messages to %t", action.SignMessages)
+
+	case *client.ChatEventStickerSetChanged:
+		event.Kind = KindStickerSetChanged
+		event.Description = fmt.Sprintf("changed sticker set from %d to %d", action.OldStickerSetID, action.NewStickerSetID)
+
+	case *client.ChatEventIsAllHistoryAvailableToggled:
+		event.Kind = KindIsAllHistoryAvailableToggled
+		event.Description = fmt.Sprintf("set all history available to %t", action.IsAllHistoryAvailable)
+
+	default:
+		if chatEvent.Action != nil {
+			event.Description = chatEvent.Action.ChatEventActionType()
+		}
+	}
+
+	return event
+}
+
+func messageID(message *client.Message) int64 {
+	if message == nil {
+		return 0
+	}
+
+	return message.ID
+}
+
+func isAdministrator(status client.ChatMemberStatus) bool {
+	switch status.(type) {
+	case *client.ChatMemberStatusCreator, *client.ChatMemberStatusAdministrator:
+		return true
+	}
+
+	return false
+}
+
+func statusName(status client.ChatMemberStatus) string {
+	switch status.(type) {
+	case *client.ChatMemberStatusCreator:
+		return "creator"
+	case *client.ChatMemberStatusAdministrator:
+		return "administrator"
+	case *client.ChatMemberStatusMember:
+		return "member"
+	case *client.ChatMemberStatusRestricted:
+		return "restricted"
+	case *client.ChatMemberStatusLeft:
+		return "left"
+	case *client.ChatMemberStatusBanned:
+		return "banned"
+	}
+
+	return "unknown"
+}
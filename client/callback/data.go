@@ -0,0 +1,73 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// MaxDataSize is maximum size of data attached to a callback button allowed by Telegram.
+const MaxDataSize = 64
+
+// Separator separates prefix and arguments of structured callback data.
+const Separator = ":"
+
+// ErrDataTooLong is error returned when callback data exceeds MaxDataSize.
+var ErrDataTooLong = errors.New("callback data is too long")
+
+// ValidateData returns error if callback data exceeds MaxDataSize.
+func ValidateData(data []byte) error {
+	if len(data) > MaxDataSize {
+		return fmt.Errorf("%s: %d bytes, max %d", ErrDataTooLong, len(data), MaxDataSize)
+	}
+
+	return nil
+}
+
+// Data builds structured callback data from the prefix and arguments joined with Separator.
+func Data(prefix string, args ...string) ([]byte, error) {
+	data := []byte(strings.Join(append([]string{prefix}, args...), Separator))
+
+	return data, ValidateData(data)
+}
+
+// JSONData builds structured callback data from the prefix and JSON encoding of the value.
+func JSONData(prefix string, value interface{}) ([]byte, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data := append([]byte(prefix+Separator), payload...)
+
+	return data, ValidateData(data)
+}
+
+// Button creates inline keyboard button sending callback data.
+func Button(text string, data []byte) (*client.InlineKeyboardButton, error) {
+	err := ValidateData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeCallback{
+			Data: data,
+		},
+	}, nil
+}
+
+func hasPrefix(data []byte, prefix string) bool {
+	if !bytes.HasPrefix(data, []byte(prefix)) {
+		return false
+	}
+
+	rest := data[len(prefix):]
+
+	return len(rest) == 0 || bytes.HasPrefix(rest, []byte(Separator))
+}
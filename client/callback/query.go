@@ -0,0 +1,136 @@
+package callback
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Query contains callback query received from a chat message or an inline message.
+type Query struct {
+	tdlibClient *client.Client
+	mu          sync.Mutex
+	answered    bool
+	prefix      string
+	// Unique query identifier
+	ID client.Int64JSON
+	// Identifier of the user who sent the query
+	SenderUserID int32
+	// Identifier of the chat, in which the query was sent; 0 for inline messages
+	ChatID int64
+	// Identifier of the message, from which the query originated; 0 for inline messages
+	MessageID int64
+	// Identifier of the inline message, from which the query originated; empty for chat messages
+	InlineMessageID string
+	// An identifier uniquely corresponding to the chat a message was sent to
+	ChatInstance client.Int64JSON
+	// Data attached to the callback button; empty for game queries
+	Data []byte
+	// Short name of the game attached to the callback button; empty for data queries
+	GameShortName string
+	// Submatches of the pattern matched the data
+	Matches []string
+}
+
+// NewQuery creates query from updateNewCallbackQuery or updateNewInlineCallbackQuery.
+func NewQuery(tdlibClient *client.Client, update client.Update) (*Query, bool) {
+	query := &Query{
+		tdlibClient: tdlibClient,
+	}
+
+	var payload client.CallbackQueryPayload
+
+	switch u := update.(type) {
+	case *client.UpdateNewCallbackQuery:
+		query.ID = u.ID
+		query.SenderUserID = u.SenderUserID
+		query.ChatID = u.ChatID
+		query.MessageID = u.MessageID
+		query.ChatInstance = u.ChatInstance
+		payload = u.Payload
+
+	case *client.UpdateNewInlineCallbackQuery:
+		query.ID = u.ID
+		query.SenderUserID = u.SenderUserID
+		query.InlineMessageID = u.InlineMessageID
+		query.ChatInstance = u.ChatInstance
+		payload = u.Payload
+
+	default:
+		return nil, false
+	}
+
+	switch p := payload.(type) {
+	case *client.CallbackQueryPayloadData:
+		query.Data = p.Data
+
+	case *client.CallbackQueryPayloadGame:
+		query.GameShortName = p.GameShortName
+	}
+
+	return query, true
+}
+
+// Args returns arguments of structured data following the matched prefix.
+func (query *Query) Args() []string {
+	rest := strings.TrimPrefix(string(query.Data), query.prefix)
+	rest = strings.TrimPrefix(rest, Separator)
+	if rest == "" {
+		return nil
+	}
+
+	return strings.Split(rest, Separator)
+}
+
+// DecodeJSON decodes JSON payload of structured data following the matched prefix into the value.
+func (query *Query) DecodeJSON(value interface{}) error {
+	rest := strings.TrimPrefix(string(query.Data), query.prefix)
+	rest = strings.TrimPrefix(rest, Separator)
+
+	return json.Unmarshal([]byte(rest), value)
+}
+
+// Answer answers the query with the text shown as a toast notification or an alert.
+func (query *Query) Answer(text string, showAlert bool) error {
+	return query.answer(&client.AnswerCallbackQueryRequest{
+		CallbackQueryID: query.ID,
+		Text:            text,
+		ShowAlert:       showAlert,
+	})
+}
+
+// AnswerURL answers the query with the URL to be opened by the user's client.
+func (query *Query) AnswerURL(url string) error {
+	return query.answer(&client.AnswerCallbackQueryRequest{
+		CallbackQueryID: query.ID,
+		URL:             url,
+	})
+}
+
+// IsAnswered returns true if the query has been already answered.
+func (query *Query) IsAnswered() bool {
+	query.mu.Lock()
+	defer query.mu.Unlock()
+
+	return query.answered
+}
+
+func (query *Query) answer(request *client.AnswerCallbackQueryRequest) error {
+	query.mu.Lock()
+	defer query.mu.Unlock()
+
+	if query.answered {
+		return nil
+	}
+
+	_, err := query.tdlibClient.AnswerCallbackQuery(request)
+	if err != nil {
+		return err
+	}
+
+	query.answered = true
+
+	return nil
+}
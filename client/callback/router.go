@@ -0,0 +1,201 @@
+package callback
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/router"
+)
+
+// Handler is a function type which handles a callback query.
+type Handler func(ctx context.Context, query *Query) error
+
+// Middleware is a function type which wraps a callback query handler with additional behaviour.
+type Middleware func(next Handler) Handler
+
+type route struct {
+	prefix  string
+	pattern *regexp.Regexp
+	game    bool
+	handler Handler
+}
+
+func (r *route) match(query *Query) ([]string, bool) {
+	switch {
+	case r.game:
+		return nil, query.GameShortName != "" && (r.prefix == "" || r.prefix == query.GameShortName)
+
+	case r.pattern != nil:
+		matches := r.pattern.FindStringSubmatch(string(query.Data))
+
+		return matches, matches != nil
+
+	default:
+		return nil, query.GameShortName == "" && hasPrefix(query.Data, r.prefix)
+	}
+}
+
+// Router dispatches callback queries to handlers matching their data.
+// Queries left unanswered by handlers are answered automatically to stop the progress indicator in user's client.
+type Router struct {
+	tdlibClient *client.Client
+	mu          sync.RWMutex
+	routes      []*route
+	middlewares []Middleware
+	errorText   string
+}
+
+// Option is a function type which adjusts router's configuration.
+type Option func(*Router)
+
+// WithErrorText configures the router to answer queries with specified alert text when their handler fails.
+func WithErrorText(text string) Option {
+	return func(router *Router) {
+		router.errorText = text
+	}
+}
+
+// New creates new callback query router.
+func New(tdlibClient *client.Client, options ...Option) *Router {
+	router := &Router{
+		tdlibClient: tdlibClient,
+	}
+
+	for _, option := range options {
+		option(router)
+	}
+
+	return router
+}
+
+// Use appends middlewares applied to every callback query handler.
+func (router *Router) Use(middlewares ...Middleware) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+// HandlePrefix registers handler for queries which data equals to the prefix or starts with the prefix followed by Separator.
+func (router *Router) HandlePrefix(prefix string, handler Handler) {
+	router.add(&route{
+		prefix:  prefix,
+		handler: handler,
+	})
+}
+
+// HandlePattern registers handler for queries which data matches the pattern. Submatches are available in Query.Matches.
+func (router *Router) HandlePattern(pattern *regexp.Regexp, handler Handler) {
+	router.add(&route{
+		pattern: pattern,
+		handler: handler,
+	})
+}
+
+// HandleGame registers handler for game queries with specified short name; empty name matches any game.
+func (router *Router) HandleGame(gameShortName string, handler Handler) {
+	router.add(&route{
+		prefix:  gameShortName,
+		game:    true,
+		handler: handler,
+	})
+}
+
+func (router *Router) add(r *route) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	router.routes = append(router.routes, r)
+}
+
+// HandleQuery handles the query by the first matching route and answers it if the handler has not.
+// It returns false if there is no route for the query.
+func (router *Router) HandleQuery(ctx context.Context, query *Query) (handled bool, err error) {
+	router.mu.RLock()
+	var handler Handler
+	for _, r := range router.routes {
+		matches, ok := r.match(query)
+		if ok {
+			query.prefix = r.prefix
+			query.Matches = matches
+			handler = r.handler
+			break
+		}
+	}
+	middlewares := router.middlewares
+	router.mu.RUnlock()
+
+	if handler == nil {
+		return false, nil
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	// the query is answered in defer, so it is answered even if the handler panics
+	returned := false
+	defer func() {
+		if query.IsAnswered() {
+			return
+		}
+
+		failed := !returned || err != nil
+		if failed && router.errorText != "" {
+			answerErr := query.Answer(router.errorText, true)
+			if returned && err == nil {
+				err = answerErr
+			}
+
+			return
+		}
+
+		answerErr := query.Answer("", false)
+		if returned && err == nil {
+			err = answerErr
+		}
+	}()
+
+	err = handler(ctx, query)
+	returned = true
+
+	return true, err
+}
+
+// Mount registers the callback query router as a handler of callback queries in the update router.
+// Queries without matching routes are left for the next routes.
+func (router *Router) Mount(updateRouter *router.Router) {
+	handler := func(ctx context.Context, update client.Update) error {
+		query, ok := NewQuery(router.tdlibClient, update)
+		if !ok {
+			return nil
+		}
+
+		_, err := router.HandleQuery(ctx, query)
+
+		return err
+	}
+
+	predicate := func(update client.Update) bool {
+		query, ok := NewQuery(router.tdlibClient, update)
+		if !ok {
+			return false
+		}
+
+		router.mu.RLock()
+		defer router.mu.RUnlock()
+
+		for _, r := range router.routes {
+			if _, ok := r.match(query); ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	updateRouter.Handle(client.TypeUpdateNewCallbackQuery, handler, predicate)
+	updateRouter.Handle(client.TypeUpdateNewInlineCallbackQuery, handler, predicate)
+}
@@ -82,6 +82,7 @@ func (client *Client) GetListener() *Listener {
 	listener := &Listener{
 		isActive: true,
 		Updates:  make(chan Type, 1024),
+		done:     make(chan struct{}),
 	}
 	client.listenerStore.Add(listener)
 
@@ -150,9 +151,7 @@ func (client *Client) receive() {
 		needGc := false
 		listeners := client.listenerStore.Listeners()
 		for _, listener := range listeners {
-			if listener.IsActive() {
-				listener.Updates <- typ
-			} else {
+			if !listener.send(typ) {
 				needGc = true
 			}
 		}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Command contains bot command parsed from a message.
+type Command struct {
+	tdlibClient *client.Client
+	// Name of the command without leading slash, in lower case
+	Name string
+	// Username of the bot the command is addressed to; empty if the command is not addressed explicitly
+	Mention string
+	// Raw text following the command
+	Args string
+	// Message which contains the command
+	Message *client.Message
+}
+
+// Fields returns arguments of the command split around white spaces.
+func (command *Command) Fields() []string {
+	return strings.Fields(command.Args)
+}
+
+// Reply sends plain text message in reply to the command.
+func (command *Command) Reply(text string) (*client.Message, error) {
+	return command.ReplyFormatted(&client.FormattedText{
+		Text: text,
+	}, nil)
+}
+
+// ReplyFormatted sends formatted text message with optional reply markup in reply to the command.
+func (command *Command) ReplyFormatted(text *client.FormattedText, replyMarkup client.ReplyMarkup) (*client.Message, error) {
+	return command.tdlibClient.SendMessage(&client.SendMessageRequest{
+		ChatID:           command.Message.ChatID,
+		ReplyToMessageID: command.Message.ID,
+		ReplyMarkup:      replyMarkup,
+		InputMessageContent: &client.InputMessageText{
+			Text: text,
+		},
+	})
+}
+
+// Parse extracts bot command from the message using its text entities.
+// The command must be placed at the very beginning of the message text.
+func Parse(message *client.Message) (*Command, bool) {
+	if message == nil || message.Content == nil {
+		return nil, false
+	}
+
+	content, ok := message.Content.(*client.MessageText)
+	if !ok || content.Text == nil {
+		return nil, false
+	}
+
+	for _, entity := range content.Text.Entities {
+		if entity.Type == nil || entity.Type.TextEntityTypeType() != client.TypeTextEntityTypeBotCommand {
+			continue
+		}
+
+		if entity.Offset != 0 {
+			return nil, false
+		}
+
+		text := utf16.Encode([]rune(content.Text.Text))
+		if int(entity.Length) > len(text) || entity.Length < 2 {
+			return nil, false
+		}
+
+		name := string(utf16.Decode(text[1:entity.Length]))
+		args := string(utf16.Decode(text[entity.Length:]))
+
+		var mention string
+		if i := strings.IndexByte(name, '@'); i >= 0 {
+			mention = name[i+1:]
+			name = name[:i]
+		}
+
+		return &Command{
+			Name:    strings.ToLower(name),
+			Mention: mention,
+			Args:    strings.TrimSpace(args),
+			Message: message,
+		}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,274 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/router"
+)
+
+// ErrDuplicateCommand is error returned when command name or alias is already registered.
+var ErrDuplicateCommand = errors.New("duplicate command")
+
+// ErrInvalidDefinition is error returned when registering a command definition without name or handler.
+var ErrInvalidDefinition = errors.New("invalid command definition")
+
+// Handler is a function type which handles a bot command.
+type Handler func(ctx context.Context, command *Command) error
+
+// Middleware is a function type which wraps a command handler with additional behaviour.
+type Middleware func(next Handler) Handler
+
+// Definition describes a bot command.
+type Definition struct {
+	// Name of the command without leading slash
+	Name string
+	// Alternative names of the command
+	Aliases []string
+	// Description shown in the help text
+	Description string
+	// True, if the command should not be shown in the help text
+	Hidden bool
+	// Handler of the command
+	Handler Handler
+	// Middlewares applied to the command handler only
+	Middlewares []Middleware
+}
+
+// Router dispatches bot commands received in new messages to registered handlers.
+type Router struct {
+	tdlibClient *client.Client
+	mu          sync.RWMutex
+	definitions []*Definition
+	commands    map[string]*Definition
+	middlewares []Middleware
+	notFound    Handler
+	// username is guarded by its own lock, so GetMe doesn't block command dispatching
+	usernameMu     sync.Mutex
+	username       string
+	usernameLoaded bool
+}
+
+// Option is a function type which adjusts router's configuration.
+type Option func(*Router)
+
+// WithUsername configures the router to use specified bot username instead of requesting it by GetMe.
+func WithUsername(username string) Option {
+	return func(router *Router) {
+		router.username = username
+		router.usernameLoaded = true
+	}
+}
+
+// WithNotFound configures the router to use specified handler for unknown commands.
+func WithNotFound(handler Handler) Option {
+	return func(router *Router) {
+		router.notFound = handler
+	}
+}
+
+// New creates new command router.
+func New(tdlibClient *client.Client, options ...Option) *Router {
+	router := &Router{
+		tdlibClient: tdlibClient,
+		commands:    map[string]*Definition{},
+	}
+
+	for _, option := range options {
+		option(router)
+	}
+
+	return router
+}
+
+// Use appends middlewares applied to every command handler.
+func (router *Router) Use(middlewares ...Middleware) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+// Register registers command definitions. The definitions are validated first,
+// so nothing is registered if any of them is invalid or duplicates a command.
+func (router *Router) Register(definitions ...*Definition) error {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	batch := map[string]bool{}
+	for _, definition := range definitions {
+		if definition == nil || definition.Handler == nil {
+			return fmt.Errorf("%s: handler is not set", ErrInvalidDefinition)
+		}
+
+		for _, name := range append([]string{definition.Name}, definition.Aliases...) {
+			command := normalize(name)
+			if command == "" {
+				return fmt.Errorf("%s: empty name", ErrInvalidDefinition)
+			}
+
+			if _, ok := router.commands[command]; ok || batch[command] {
+				return fmt.Errorf("%s: /%s", ErrDuplicateCommand, name)
+			}
+			batch[command] = true
+		}
+	}
+
+	for _, definition := range definitions {
+		for _, name := range append([]string{definition.Name}, definition.Aliases...) {
+			router.commands[normalize(name)] = definition
+		}
+		router.definitions = append(router.definitions, definition)
+	}
+
+	return nil
+}
+
+// Handle registers handler for the command with specified name and description.
+func (router *Router) Handle(name string, description string, handler Handler, middlewares ...Middleware) error {
+	return router.Register(&Definition{
+		Name:        name,
+		Description: description,
+		Handler:     handler,
+		Middlewares: middlewares,
+	})
+}
+
+// Help returns help text listing all visible commands sorted by name.
+func (router *Router) Help() string {
+	router.mu.RLock()
+	definitions := make([]*Definition, 0, len(router.definitions))
+	for _, definition := range router.definitions {
+		if !definition.Hidden {
+			definitions = append(definitions, definition)
+		}
+	}
+	router.mu.RUnlock()
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+
+	var lines []string
+	for _, definition := range definitions {
+		line := "/" + normalize(definition.Name)
+		for _, alias := range definition.Aliases {
+			line += ", /" + normalize(alias)
+		}
+		if definition.Description != "" {
+			line += " - " + definition.Description
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// HelpHandler returns handler which replies with the help text.
+func (router *Router) HelpHandler() Handler {
+	return func(ctx context.Context, command *Command) error {
+		_, err := command.Reply(router.Help())
+
+		return err
+	}
+}
+
+// Username returns username of the bot. It is requested by GetMe once and cached; failed requests are retried by the next call.
+func (router *Router) Username() (string, error) {
+	router.usernameMu.Lock()
+	defer router.usernameMu.Unlock()
+
+	if router.usernameLoaded {
+		return router.username, nil
+	}
+
+	me, err := router.tdlibClient.GetMe()
+	if err != nil {
+		return "", err
+	}
+
+	router.username = me.Username
+	router.usernameLoaded = true
+
+	return router.username, nil
+}
+
+// Match parses the command from the message and reports whether it should be handled by the router.
+// Commands explicitly addressed to other bots are never matched.
+func (router *Router) Match(message *client.Message) (*Command, bool) {
+	command, ok := Parse(message)
+	if !ok {
+		return nil, false
+	}
+
+	if command.Mention != "" {
+		username, err := router.Username()
+		if err != nil || !strings.EqualFold(command.Mention, username) {
+			return nil, false
+		}
+	}
+
+	command.tdlibClient = router.tdlibClient
+
+	router.mu.RLock()
+	_, ok = router.commands[command.Name]
+	router.mu.RUnlock()
+
+	return command, ok || router.notFound != nil
+}
+
+// HandleMessage handles bot command contained in the message.
+// It returns false if the message does not contain a command handled by the router.
+func (router *Router) HandleMessage(ctx context.Context, message *client.Message) (bool, error) {
+	command, ok := router.Match(message)
+	if !ok {
+		return false, nil
+	}
+
+	router.mu.RLock()
+	definition := router.commands[command.Name]
+	middlewares := router.middlewares
+	router.mu.RUnlock()
+
+	handler := router.notFound
+	if definition != nil {
+		handler = definition.Handler
+		for i := len(definition.Middlewares) - 1; i >= 0; i-- {
+			handler = definition.Middlewares[i](handler)
+		}
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return true, handler(ctx, command)
+}
+
+// Mount registers the command router as a handler of new messages in the update router.
+// Messages without commands handled by the command router are left for the next routes.
+func (router *Router) Mount(updateRouter *router.Router) {
+	updateRouter.Handle(client.TypeUpdateNewMessage, func(ctx context.Context, update client.Update) error {
+		_, err := router.HandleMessage(ctx, update.(*client.UpdateNewMessage).Message)
+
+		return err
+	}, func(update client.Update) bool {
+		newMessage, ok := update.(*client.UpdateNewMessage)
+		if !ok || newMessage.Message == nil || newMessage.Message.IsOutgoing {
+			return false
+		}
+
+		_, ok = router.Match(newMessage.Message)
+
+		return ok
+	})
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimPrefix(name, "/"))
+}
@@ -0,0 +1,44 @@
+package compose
+
+import (
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Local returns input file read from the local path.
+func Local(path string) client.InputFile {
+	return &client.InputFileLocal{
+		Path: path,
+	}
+}
+
+// Remote returns input file identified by the remote identifier or by the HTTP URL.
+func Remote(id string) client.InputFile {
+	return &client.InputFileRemote{
+		ID: id,
+	}
+}
+
+// ID returns input file identified by the unique file identifier known to TDLib.
+func ID(id int32) client.InputFile {
+	return &client.InputFileID{
+		ID: id,
+	}
+}
+
+// Generated returns input file generated by the client from the original path using the conversion.
+func Generated(originalPath string, conversion string, expectedSize int32) client.InputFile {
+	return &client.InputFileGenerated{
+		OriginalPath: originalPath,
+		Conversion:   conversion,
+		ExpectedSize: expectedSize,
+	}
+}
+
+// Thumbnail returns thumbnail of the size made from the file.
+func Thumbnail(file client.InputFile, width int32, height int32) *client.InputThumbnail {
+	return &client.InputThumbnail{
+		Thumbnail: file,
+		Width:     width,
+		Height:    height,
+	}
+}
@@ -0,0 +1,278 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// MaxAlbumSize is maximum number of messages in an album.
+const MaxAlbumSize = 10
+
+var (
+	// ErrNoContent is error returned when no content is added to the message.
+	ErrNoContent = errors.New("message has no content")
+	// ErrNotAlbum is error returned when a single message request is built from several contents.
+	ErrNotAlbum = errors.New("message has several contents, album is expected")
+	// ErrInvalidAlbum is error returned when contents can not be sent as an album.
+	ErrInvalidAlbum = errors.New("invalid album")
+)
+
+// MessageBuilder composes message content and options of sending a message or an album.
+// Caption and thumbnail are applied to the last added content.
+type MessageBuilder struct {
+	chatID              int64
+	replyToMessageID    int64
+	disableNotification bool
+	fromBackground      bool
+	replyMarkup         client.ReplyMarkup
+	contents            []client.InputMessageContent
+}
+
+// To creates new message builder for the chat.
+func To(chatID int64) *MessageBuilder {
+	return &MessageBuilder{
+		chatID: chatID,
+	}
+}
+
+// ReplyTo sends the message in reply to the message.
+func (builder *MessageBuilder) ReplyTo(messageID int64) *MessageBuilder {
+	builder.replyToMessageID = messageID
+
+	return builder
+}
+
+// DisableNotification sends the message silently.
+func (builder *MessageBuilder) DisableNotification() *MessageBuilder {
+	builder.disableNotification = true
+
+	return builder
+}
+
+// FromBackground marks the message as sent from the background.
+func (builder *MessageBuilder) FromBackground() *MessageBuilder {
+	builder.fromBackground = true
+
+	return builder
+}
+
+// ReplyMarkup attaches the reply markup to the message. Albums can not have reply markup.
+func (builder *MessageBuilder) ReplyMarkup(replyMarkup client.ReplyMarkup) *MessageBuilder {
+	builder.replyMarkup = replyMarkup
+
+	return builder
+}
+
+// Content adds arbitrary content.
+func (builder *MessageBuilder) Content(content client.InputMessageContent) *MessageBuilder {
+	builder.contents = append(builder.contents, content)
+
+	return builder
+}
+
+// Text adds text content.
+func (builder *MessageBuilder) Text(text *client.FormattedText) *MessageBuilder {
+	return builder.Content(&client.InputMessageText{
+		Text: text,
+	})
+}
+
+// PlainText adds text content without entities.
+func (builder *MessageBuilder) PlainText(text string) *MessageBuilder {
+	return builder.Text(Plain(text))
+}
+
+// DisableWebPagePreview disables web page preview of the last added text content.
+func (builder *MessageBuilder) DisableWebPagePreview() *MessageBuilder {
+	if content, ok := builder.last().(*client.InputMessageText); ok {
+		content.DisableWebPagePreview = true
+	}
+
+	return builder
+}
+
+// Photo adds photo content.
+func (builder *MessageBuilder) Photo(file client.InputFile) *MessageBuilder {
+	return builder.Content(&client.InputMessagePhoto{
+		Photo: file,
+	})
+}
+
+// Video adds video content.
+func (builder *MessageBuilder) Video(file client.InputFile) *MessageBuilder {
+	return builder.Content(&client.InputMessageVideo{
+		Video: file,
+	})
+}
+
+// Document adds document content.
+func (builder *MessageBuilder) Document(file client.InputFile) *MessageBuilder {
+	return builder.Content(&client.InputMessageDocument{
+		Document: file,
+	})
+}
+
+// Audio adds audio content.
+func (builder *MessageBuilder) Audio(file client.InputFile, title string, performer string) *MessageBuilder {
+	return builder.Content(&client.InputMessageAudio{
+		Audio:     file,
+		Title:     title,
+		Performer: performer,
+	})
+}
+
+// Animation adds animation content.
+func (builder *MessageBuilder) Animation(file client.InputFile) *MessageBuilder {
+	return builder.Content(&client.InputMessageAnimation{
+		Animation: file,
+	})
+}
+
+// VoiceNote adds voice note content.
+func (builder *MessageBuilder) VoiceNote(file client.InputFile, duration int32) *MessageBuilder {
+	return builder.Content(&client.InputMessageVoiceNote{
+		VoiceNote: file,
+		Duration:  duration,
+	})
+}
+
+// Sticker adds sticker content.
+func (builder *MessageBuilder) Sticker(file client.InputFile) *MessageBuilder {
+	return builder.Content(&client.InputMessageSticker{
+		Sticker: file,
+	})
+}
+
+// Caption sets caption of the last added media content.
+func (builder *MessageBuilder) Caption(caption *client.FormattedText) *MessageBuilder {
+	switch content := builder.last().(type) {
+	case *client.InputMessagePhoto:
+		content.Caption = caption
+	case *client.InputMessageVideo:
+		content.Caption = caption
+	case *client.InputMessageDocument:
+		content.Caption = caption
+	case *client.InputMessageAudio:
+		content.Caption = caption
+	case *client.InputMessageAnimation:
+		content.Caption = caption
+	case *client.InputMessageVoiceNote:
+		content.Caption = caption
+	}
+
+	return builder
+}
+
+// Thumbnail sets thumbnail of the last added media content.
+func (builder *MessageBuilder) Thumbnail(thumbnail *client.InputThumbnail) *MessageBuilder {
+	switch content := builder.last().(type) {
+	case *client.InputMessagePhoto:
+		content.Thumbnail = thumbnail
+	case *client.InputMessageVideo:
+		content.Thumbnail = thumbnail
+	case *client.InputMessageDocument:
+		content.Thumbnail = thumbnail
+	case *client.InputMessageAudio:
+		content.AlbumCoverThumbnail = thumbnail
+	case *client.InputMessageAnimation:
+		content.Thumbnail = thumbnail
+	case *client.InputMessageSticker:
+		content.Thumbnail = thumbnail
+	}
+
+	return builder
+}
+
+func (builder *MessageBuilder) last() client.InputMessageContent {
+	if len(builder.contents) == 0 {
+		return nil
+	}
+
+	return builder.contents[len(builder.contents)-1]
+}
+
+// IsAlbum returns true if several contents are added.
+func (builder *MessageBuilder) IsAlbum() bool {
+	return len(builder.contents) > 1
+}
+
+// Request returns request sending a single message.
+func (builder *MessageBuilder) Request() (*client.SendMessageRequest, error) {
+	switch {
+	case len(builder.contents) == 0:
+		return nil, ErrNoContent
+	case len(builder.contents) > 1:
+		return nil, ErrNotAlbum
+	}
+
+	return &client.SendMessageRequest{
+		ChatID:              builder.chatID,
+		ReplyToMessageID:    builder.replyToMessageID,
+		DisableNotification: builder.disableNotification,
+		FromBackground:      builder.fromBackground,
+		ReplyMarkup:         builder.replyMarkup,
+		InputMessageContent: builder.contents[0],
+	}, nil
+}
+
+// AlbumRequest returns request sending the contents as an album of photos and videos.
+func (builder *MessageBuilder) AlbumRequest() (*client.SendMessageAlbumRequest, error) {
+	if len(builder.contents) == 0 {
+		return nil, ErrNoContent
+	}
+
+	if len(builder.contents) > MaxAlbumSize {
+		return nil, fmt.Errorf("%s: %d contents, max %d", ErrInvalidAlbum, len(builder.contents), MaxAlbumSize)
+	}
+
+	if builder.replyMarkup != nil {
+		return nil, fmt.Errorf("%s: reply markup is not supported", ErrInvalidAlbum)
+	}
+
+	for i, content := range builder.contents {
+		switch content.(type) {
+		case *client.InputMessagePhoto, *client.InputMessageVideo:
+		default:
+			return nil, fmt.Errorf("%s: content %d is %s, only photos and videos are allowed", ErrInvalidAlbum, i, content.InputMessageContentType())
+		}
+	}
+
+	return &client.SendMessageAlbumRequest{
+		ChatID:               builder.chatID,
+		ReplyToMessageID:     builder.replyToMessageID,
+		DisableNotification:  builder.disableNotification,
+		FromBackground:       builder.fromBackground,
+		InputMessageContents: builder.contents,
+	}, nil
+}
+
+// Send sends the message or the album and returns sent messages.
+func (builder *MessageBuilder) Send(tdlibClient *client.Client) ([]*client.Message, error) {
+	if builder.IsAlbum() {
+		request, err := builder.AlbumRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err := tdlibClient.SendMessageAlbum(request)
+		if err != nil {
+			return nil, err
+		}
+
+		return messages.Messages, nil
+	}
+
+	request, err := builder.Request()
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := tdlibClient.SendMessage(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*client.Message{message}, nil
+}
@@ -0,0 +1,105 @@
+package compose
+
+import (
+	"unicode/utf16"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// TextBuilder builds formatted text appending pieces of text with their entities.
+// Entity offsets are calculated in UTF-16 code units as required by TDLib.
+type TextBuilder struct {
+	text     []rune
+	length   int32
+	entities []*client.TextEntity
+}
+
+// NewText creates new text builder.
+func NewText() *TextBuilder {
+	return &TextBuilder{}
+}
+
+// Plain appends text without entities.
+func (builder *TextBuilder) Plain(text string) *TextBuilder {
+	runes := []rune(text)
+	builder.text = append(builder.text, runes...)
+	builder.length += int32(len(utf16.Encode(runes)))
+
+	return builder
+}
+
+// Entity appends text covered by an entity of the type.
+func (builder *TextBuilder) Entity(text string, entityType client.TextEntityType) *TextBuilder {
+	offset := builder.length
+	builder.Plain(text)
+
+	if builder.length > offset {
+		builder.entities = append(builder.entities, &client.TextEntity{
+			Offset: offset,
+			Length: builder.length - offset,
+			Type:   entityType,
+		})
+	}
+
+	return builder
+}
+
+// Bold appends bold text.
+func (builder *TextBuilder) Bold(text string) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypeBold{})
+}
+
+// Italic appends italic text.
+func (builder *TextBuilder) Italic(text string) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypeItalic{})
+}
+
+// Code appends inline code.
+func (builder *TextBuilder) Code(text string) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypeCode{})
+}
+
+// Pre appends preformatted text.
+func (builder *TextBuilder) Pre(text string) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypePre{})
+}
+
+// PreCode appends preformatted code written in the programming language.
+func (builder *TextBuilder) PreCode(text string, language string) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypePreCode{
+		Language: language,
+	})
+}
+
+// TextURL appends text linking to the URL.
+func (builder *TextBuilder) TextURL(text string, url string) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypeTextURL{
+		URL: url,
+	})
+}
+
+// MentionName appends text mentioning the user by its identifier.
+func (builder *TextBuilder) MentionName(text string, userID int32) *TextBuilder {
+	return builder.Entity(text, &client.TextEntityTypeMentionName{
+		UserID: userID,
+	})
+}
+
+// Build returns the formatted text.
+func (builder *TextBuilder) Build() *client.FormattedText {
+	entities := make([]*client.TextEntity, len(builder.entities))
+	copy(entities, builder.entities)
+
+	return &client.FormattedText{
+		Text:     string(builder.text),
+		Entities: entities,
+	}
+}
+
+// Plain returns formatted text without entities.
+func Plain(text string) *client.FormattedText {
+	return &client.FormattedText{
+		Text:     text,
+		Entities: []*client.TextEntity{},
+	}
+}
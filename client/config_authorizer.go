@@ -0,0 +1,307 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ConfigAuthorizer implements AuthorizationStateHandler interface taking credentials from providers,
+// so authorization does not need a terminal.
+type ConfigAuthorizer struct {
+	parameters       *TdlibParameters
+	phoneNumber      Provider
+	botToken         Provider
+	code             Provider
+	password         Provider
+	firstName        Provider
+	lastName         Provider
+	encryptionKey    Provider
+	newEncryptionKey Provider
+	reporter         func(state AuthorizationState)
+	// true if the database is opened with the new encryption key, so it doesn't need to be changed
+	openedWithNewKey bool
+	mu               sync.Mutex
+	state            AuthorizationState
+}
+
+// AuthorizerOption is a function type which adjusts config authorizer's configuration.
+// Its constructors are prefixed with WithAuth to distinguish them from client options.
+type AuthorizerOption func(*ConfigAuthorizer)
+
+// WithAuthPhoneNumber configures the authorizer to log in as a user with the phone number from the provider.
+func WithAuthPhoneNumber(provider Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.phoneNumber = provider
+	}
+}
+
+// WithAuthBotToken configures the authorizer to log in as a bot with the token from the provider.
+func WithAuthBotToken(provider Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.botToken = provider
+	}
+}
+
+// WithAuthCode configures the authorizer to take the authentication code from the provider.
+func WithAuthCode(provider Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.code = provider
+	}
+}
+
+// WithAuthPassword configures the authorizer to take the two-step verification password from the provider.
+func WithAuthPassword(provider Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.password = provider
+	}
+}
+
+// WithAuthRegistration configures the authorizer to register a new user with the names from the providers.
+// The last name provider may be nil.
+func WithAuthRegistration(firstName Provider, lastName Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.firstName = firstName
+		a.lastName = lastName
+	}
+}
+
+// WithAuthEncryptionKey configures the authorizer to open TDLib database with the encryption key from the provider.
+// The database is not encrypted by default.
+func WithAuthEncryptionKey(provider Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.encryptionKey = provider
+	}
+}
+
+// WithAuthNewEncryptionKey configures the authorizer to change the database encryption key to the key from the provider
+// after authorization. The new key must be used to open the database next time.
+// The database opened with the new key is not changed, so the change is not repeated if the old key is still configured.
+func WithAuthNewEncryptionKey(provider Provider) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.newEncryptionKey = provider
+	}
+}
+
+// WithAuthStateReporter configures the authorizer to call the reporter with every authorization state before handling it.
+func WithAuthStateReporter(reporter func(state AuthorizationState)) AuthorizerOption {
+	return func(a *ConfigAuthorizer) {
+		a.reporter = reporter
+	}
+}
+
+// NewConfigAuthorizer creates new instance of ConfigAuthorizer.
+func NewConfigAuthorizer(parameters *TdlibParameters, options ...AuthorizerOption) *ConfigAuthorizer {
+	a := &ConfigAuthorizer{
+		parameters: parameters,
+	}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	return a
+}
+
+// State returns the last handled authorization state; nil if authorization is not started.
+func (a *ConfigAuthorizer) State() AuthorizationState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.state
+}
+
+// Waiting returns name of the credential which authorization is waiting for; empty string if it is not waiting.
+func (a *ConfigAuthorizer) Waiting() string {
+	state := a.State()
+	if state == nil {
+		return ""
+	}
+
+	return waitingStep(state)
+}
+
+// Handle is a function called during authorization.
+func (a *ConfigAuthorizer) Handle(client *Client, state AuthorizationState) error {
+	a.mu.Lock()
+	a.state = state
+	a.mu.Unlock()
+
+	if a.reporter != nil {
+		a.reporter(state)
+	}
+
+	switch state.AuthorizationStateType() {
+	case TypeAuthorizationStateWaitTdlibParameters:
+		_, err := client.SetTdlibParameters(&SetTdlibParametersRequest{
+			Parameters: a.parameters,
+		})
+		return err
+
+	case TypeAuthorizationStateWaitEncryptionKey:
+		var encryptionKey string
+		if a.encryptionKey != nil {
+			var err error
+			encryptionKey, err = a.provide(a.encryptionKey, state)
+			if err != nil {
+				return err
+			}
+		} else if state.(*AuthorizationStateWaitEncryptionKey).IsEncrypted && a.newEncryptionKey == nil {
+			return fmt.Errorf("%s: database is encrypted, but encryption key provider is not configured", waitingStep(state))
+		}
+
+		_, err := client.CheckDatabaseEncryptionKey(&CheckDatabaseEncryptionKeyRequest{
+			EncryptionKey: []byte(encryptionKey),
+		})
+		if err == nil || a.newEncryptionKey == nil {
+			return err
+		}
+
+		// the key may be already changed to the new one before
+		newEncryptionKey, newErr := a.provide(a.newEncryptionKey, state)
+		if newErr != nil {
+			return fmt.Errorf("%s; new key: %s", err, newErr)
+		}
+
+		_, newErr = client.CheckDatabaseEncryptionKey(&CheckDatabaseEncryptionKeyRequest{
+			EncryptionKey: []byte(newEncryptionKey),
+		})
+		if newErr != nil {
+			return fmt.Errorf("%s; new key: %s", err, newErr)
+		}
+		a.openedWithNewKey = true
+
+		return nil
+
+	case TypeAuthorizationStateWaitPhoneNumber:
+		if a.botToken != nil {
+			token, err := a.provide(a.botToken, state)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.CheckAuthenticationBotToken(&CheckAuthenticationBotTokenRequest{
+				Token: token,
+			})
+			return err
+		}
+
+		phoneNumber, err := a.provide(a.phoneNumber, state)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.SetAuthenticationPhoneNumber(&SetAuthenticationPhoneNumberRequest{
+			PhoneNumber: phoneNumber,
+		})
+		return err
+
+	case TypeAuthorizationStateWaitCode:
+		code, err := a.provide(a.code, state)
+		if err != nil {
+			return err
+		}
+
+		var firstName, lastName string
+		if !state.(*AuthorizationStateWaitCode).IsRegistered {
+			firstName, err = a.provide(a.firstName, state)
+			if err != nil {
+				return err
+			}
+
+			if a.lastName != nil {
+				lastName, err = a.provide(a.lastName, state)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = client.CheckAuthenticationCode(&CheckAuthenticationCodeRequest{
+			Code:      code,
+			FirstName: firstName,
+			LastName:  lastName,
+		})
+		return err
+
+	case TypeAuthorizationStateWaitPassword:
+		password, err := a.provide(a.password, state)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.CheckAuthenticationPassword(&CheckAuthenticationPasswordRequest{
+			Password: password,
+		})
+		return err
+
+	case TypeAuthorizationStateReady:
+		if a.newEncryptionKey == nil || a.openedWithNewKey {
+			return nil
+		}
+
+		newEncryptionKey, err := a.provide(a.newEncryptionKey, state)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.SetDatabaseEncryptionKey(&SetDatabaseEncryptionKeyRequest{
+			NewEncryptionKey: []byte(newEncryptionKey),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// Close is a function called when authorization is done or cancelled.
+func (a *ConfigAuthorizer) Close() {
+}
+
+// provide returns the credential waited by the state from the provider.
+func (a *ConfigAuthorizer) provide(provider Provider, state AuthorizationState) (string, error) {
+	if provider == nil {
+		return "", fmt.Errorf("%s: provider is not configured", step(state))
+	}
+
+	value, err := provider(state)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", step(state), err)
+	}
+
+	return value, nil
+}
+
+// step returns name of the credential which is provided in the state.
+func step(state AuthorizationState) string {
+	if state.AuthorizationStateType() == TypeAuthorizationStateReady {
+		return "new encryption key"
+	}
+
+	return waitingStep(state)
+}
+
+// waitingStep returns name of the credential which the state is waiting for; empty string if it is not waiting.
+func waitingStep(state AuthorizationState) string {
+	switch state.AuthorizationStateType() {
+	case TypeAuthorizationStateWaitTdlibParameters:
+		return "tdlib parameters"
+
+	case TypeAuthorizationStateWaitEncryptionKey:
+		return "encryption key"
+
+	case TypeAuthorizationStateWaitPhoneNumber:
+		return "phone number"
+
+	case TypeAuthorizationStateWaitCode:
+		if !state.(*AuthorizationStateWaitCode).IsRegistered {
+			return "code and name"
+		}
+
+		return "code"
+
+	case TypeAuthorizationStateWaitPassword:
+		return "password"
+	}
+
+	return ""
+}
@@ -0,0 +1,228 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/callback"
+)
+
+// End is a name of the state which finishes a conversation.
+const End = ""
+
+var (
+	// ErrTimeout is error returned by Ask when the user has not replied in time.
+	ErrTimeout = errors.New("conversation timeout")
+	// ErrCancelled is error returned by Ask when the conversation is cancelled by the user or by the manager.
+	ErrCancelled = errors.New("conversation cancelled")
+)
+
+// StateFunc is a function type which performs a step of a conversation and returns name of the next state.
+type StateFunc func(ctx context.Context, conversation *Conversation) (string, error)
+
+// Flow declares states of a conversation.
+type Flow struct {
+	// Name of the first state
+	Initial string
+	// States by their names
+	States map[string]StateFunc
+}
+
+// Reply contains a message or a callback query sent by the user in reply to a question.
+type Reply struct {
+	// Message sent by the user; nil if the user pressed a callback button
+	Message *client.Message
+	// Callback query sent by the user; nil if the user sent a message. The query is answered by the manager
+	Query *callback.Query
+}
+
+// Text returns text of the message or data of the callback query.
+func (reply *Reply) Text() string {
+	if reply.Query != nil {
+		return string(reply.Query.Data)
+	}
+
+	if reply.Message != nil {
+		if content, ok := reply.Message.Content.(*client.MessageText); ok && content.Text != nil {
+			return content.Text.Text
+		}
+	}
+
+	return ""
+}
+
+// Conversation contains state of a dialog with a user in a chat.
+type Conversation struct {
+	manager *Manager
+	replies chan *Reply
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	waiting bool
+	// Identifier of the chat
+	ChatID int64
+	// Identifier of the user whose replies are accepted; 0 if any user of the chat is accepted
+	UserID int32
+	// Name of the flow
+	Flow string
+	// Name of the current state
+	State string
+	// Arbitrary data collected during the conversation
+	Data map[string]string
+}
+
+func newConversation(manager *Manager, chatID int64, userID int32, flow string) *Conversation {
+	return &Conversation{
+		manager: manager,
+		replies: make(chan *Reply, 1),
+		done:    make(chan struct{}),
+		ChatID:  chatID,
+		UserID:  userID,
+		Flow:    flow,
+		Data:    map[string]string{},
+	}
+}
+
+// Get returns value collected during the conversation.
+func (conversation *Conversation) Get(key string) string {
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+
+	return conversation.Data[key]
+}
+
+// Set stores value collected during the conversation. It is persisted on the next state transition.
+func (conversation *Conversation) Set(key string, value string) {
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+
+	conversation.Data[key] = value
+}
+
+// Send sends plain text message to the chat of the conversation.
+func (conversation *Conversation) Send(text string, replyMarkup client.ReplyMarkup) (*client.Message, error) {
+	return conversation.manager.tdlibClient.SendMessage(&client.SendMessageRequest{
+		ChatID:      conversation.ChatID,
+		ReplyMarkup: replyMarkup,
+		InputMessageContent: &client.InputMessageText{
+			Text: &client.FormattedText{
+				Text: text,
+			},
+		},
+	})
+}
+
+// Ask sends the prompt, if it is not empty, and waits for the user's next message or callback query.
+func (conversation *Conversation) Ask(ctx context.Context, prompt string) (*Reply, error) {
+	return conversation.AskWithMarkup(ctx, prompt, nil)
+}
+
+// AskWithMarkup sends the prompt with the reply markup and waits for the user's next message or callback query.
+func (conversation *Conversation) AskWithMarkup(ctx context.Context, prompt string, replyMarkup client.ReplyMarkup) (*Reply, error) {
+	if prompt != "" {
+		_, err := conversation.Send(prompt, replyMarkup)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	conversation.wait()
+	defer conversation.stopWaiting()
+
+	var timeout <-chan time.Time
+	if conversation.manager.timeout > 0 {
+		timer := time.NewTimer(conversation.manager.timeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case reply := <-conversation.replies:
+		return reply, nil
+
+	case <-conversation.done:
+		return nil, ErrCancelled
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case <-timeout:
+		return nil, ErrTimeout
+	}
+}
+
+// Cancel cancels the conversation. Pending and following calls of Ask return ErrCancelled.
+func (conversation *Conversation) Cancel() {
+	conversation.once.Do(func() {
+		close(conversation.done)
+	})
+}
+
+// wait starts waiting for a reply dropping replies which arrived after the previous question is finished.
+func (conversation *Conversation) wait() {
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+
+	for {
+		select {
+		case <-conversation.replies:
+		default:
+			conversation.waiting = true
+
+			return
+		}
+	}
+}
+
+func (conversation *Conversation) stopWaiting() {
+	conversation.mu.Lock()
+	conversation.waiting = false
+	conversation.mu.Unlock()
+}
+
+// isWaiting returns true if the conversation is waiting for a reply.
+func (conversation *Conversation) isWaiting() bool {
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+
+	return conversation.waiting
+}
+
+// deliver delivers the reply to the pending question. It returns false if the conversation is not waiting for a reply.
+func (conversation *Conversation) deliver(reply *Reply) bool {
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+
+	if !conversation.waiting {
+		return false
+	}
+
+	// only one reply is accepted per question
+	conversation.waiting = false
+	conversation.replies <- reply
+
+	return true
+}
+
+func (conversation *Conversation) snapshot() *Snapshot {
+	conversation.mu.Lock()
+	defer conversation.mu.Unlock()
+
+	data := make(map[string]string, len(conversation.Data))
+	for key, value := range conversation.Data {
+		data[key] = value
+	}
+
+	return &Snapshot{
+		ChatID:    conversation.ChatID,
+		UserID:    conversation.UserID,
+		Flow:      conversation.Flow,
+		State:     conversation.State,
+		Data:      data,
+		UpdatedAt: time.Now(),
+	}
+}
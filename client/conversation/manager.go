@@ -0,0 +1,364 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/callback"
+	"github.com/u-robot/go-tdlib/client/command"
+	"github.com/u-robot/go-tdlib/client/router"
+)
+
+var (
+	// ErrConversationActive is error returned when the chat already has an active conversation.
+	ErrConversationActive = errors.New("conversation is already active")
+	// ErrUnknownFlow is error returned when the flow is not registered.
+	ErrUnknownFlow = errors.New("unknown conversation flow")
+	// ErrUnknownState is error returned when the flow has no state with the name.
+	ErrUnknownState = errors.New("unknown conversation state")
+)
+
+// Manager runs conversations and delivers users' replies to them.
+// Replies to pending questions are consumed by the manager and are not passed to other handlers of the update router.
+// Messages sent while no question is pending are left for other handlers.
+type Manager struct {
+	tdlibClient    *client.Client
+	mu             sync.Mutex
+	flows          map[string]*Flow
+	conversations  map[int64]*Conversation
+	store          Store
+	timeout        time.Duration
+	cancelCommands []string
+	cancelText     string
+	errorHandler   func(conversation *Conversation, err error)
+}
+
+// Option is a function type which adjusts manager's configuration.
+type Option func(*Manager)
+
+// WithStore configures the manager to persist conversation states in specified store.
+func WithStore(store Store) Option {
+	return func(manager *Manager) {
+		manager.store = store
+	}
+}
+
+// WithTimeout configures the manager to stop waiting for a reply after specified timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(manager *Manager) {
+		manager.timeout = timeout
+	}
+}
+
+// WithCancelCommands configures the manager to cancel conversations on specified bot commands (e.g. "cancel")
+// and to reply with the text, if it is not empty.
+func WithCancelCommands(text string, commands ...string) Option {
+	return func(manager *Manager) {
+		manager.cancelText = text
+		manager.cancelCommands = commands
+	}
+}
+
+// WithErrorHandler configures the manager to use specified handler of errors finishing conversations.
+func WithErrorHandler(errorHandler func(conversation *Conversation, err error)) Option {
+	return func(manager *Manager) {
+		manager.errorHandler = errorHandler
+	}
+}
+
+// New creates new conversation manager.
+func New(tdlibClient *client.Client, options ...Option) *Manager {
+	manager := &Manager{
+		tdlibClient:   tdlibClient,
+		flows:         map[string]*Flow{},
+		conversations: map[int64]*Conversation{},
+		store:         NewMemoryStore(),
+		timeout:       5 * time.Minute,
+		errorHandler: func(conversation *Conversation, err error) {
+			log.Printf("Conversation %s in chat %d finished with error: %s\n", conversation.Flow, conversation.ChatID, err)
+		},
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager
+}
+
+// Register registers the flow with the name.
+func (manager *Manager) Register(name string, flow *Flow) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	manager.flows[name] = flow
+}
+
+// Start starts the flow in the chat in a separate goroutine. If userID is not 0 only replies of the user are accepted.
+func (manager *Manager) Start(ctx context.Context, chatID int64, userID int32, flowName string) (*Conversation, error) {
+	conversation := newConversation(manager, chatID, userID, flowName)
+
+	return conversation, manager.start(ctx, conversation, "")
+}
+
+// Resume restarts all conversations persisted in the store from their saved states.
+func (manager *Manager) Resume(ctx context.Context) error {
+	snapshots, err := manager.store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		conversation := newConversation(manager, snapshot.ChatID, snapshot.UserID, snapshot.Flow)
+		if snapshot.Data != nil {
+			conversation.Data = snapshot.Data
+		}
+
+		err = manager.start(ctx, conversation, snapshot.State)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (manager *Manager) start(ctx context.Context, conversation *Conversation, state string) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	flow, ok := manager.flows[conversation.Flow]
+	if !ok {
+		return fmt.Errorf("%s: %s", ErrUnknownFlow, conversation.Flow)
+	}
+
+	if _, ok := manager.conversations[conversation.ChatID]; ok {
+		return ErrConversationActive
+	}
+
+	if state == "" {
+		state = flow.Initial
+	}
+	conversation.State = state
+
+	manager.conversations[conversation.ChatID] = conversation
+
+	go manager.run(ctx, conversation, flow)
+
+	return nil
+}
+
+func (manager *Manager) run(ctx context.Context, conversation *Conversation, flow *Flow) {
+	var err error
+
+	defer func() {
+		// conversations interrupted by the context are kept in the store to be resumed later
+		manager.finish(conversation, ctx.Err() == nil)
+
+		// cancellation and timeout are regular ways to finish a conversation
+		if err != nil && err != ErrCancelled && err != ErrTimeout && manager.errorHandler != nil {
+			manager.errorHandler(conversation, err)
+		}
+	}()
+
+	for conversation.State != End {
+		stateFunc, ok := flow.States[conversation.State]
+		if !ok {
+			err = fmt.Errorf("%s: %s", ErrUnknownState, conversation.State)
+
+			return
+		}
+
+		err = manager.store.Save(conversation.snapshot())
+		if err != nil {
+			return
+		}
+
+		var next string
+		next, err = stateFunc(ctx, conversation)
+		if err != nil {
+			return
+		}
+
+		conversation.mu.Lock()
+		conversation.State = next
+		conversation.mu.Unlock()
+	}
+}
+
+func (manager *Manager) finish(conversation *Conversation, forget bool) {
+	manager.mu.Lock()
+	if manager.conversations[conversation.ChatID] == conversation {
+		delete(manager.conversations, conversation.ChatID)
+	}
+	manager.mu.Unlock()
+
+	conversation.Cancel()
+
+	if forget && conversation.Flow != "" {
+		err := manager.store.Delete(conversation.ChatID)
+		if err != nil {
+			log.Printf("Unable to delete conversation state of chat %d: %s\n", conversation.ChatID, err)
+		}
+	}
+}
+
+// Ask sends the prompt to the chat and waits for the next message or callback query from any user of the chat.
+// If the chat has an active conversation the question is asked within it.
+// Ask must not be called from the update router's handler of the same chat, because replies are
+// delivered by the router sequentially; run it in a separate goroutine or use flows instead.
+func (manager *Manager) Ask(ctx context.Context, chatID int64, prompt string) (*Reply, error) {
+	manager.mu.Lock()
+	conversation, ok := manager.conversations[chatID]
+	if !ok {
+		conversation = newConversation(manager, chatID, 0, "")
+		manager.conversations[chatID] = conversation
+	}
+	manager.mu.Unlock()
+
+	if !ok {
+		defer manager.finish(conversation, true)
+	}
+
+	return conversation.Ask(ctx, prompt)
+}
+
+// Cancel cancels active conversation in the chat. It returns false if the chat has no active conversation.
+func (manager *Manager) Cancel(chatID int64) bool {
+	manager.mu.Lock()
+	conversation, ok := manager.conversations[chatID]
+	manager.mu.Unlock()
+
+	if ok {
+		conversation.Cancel()
+	}
+
+	return ok
+}
+
+// Active returns active conversation in the chat.
+func (manager *Manager) Active(chatID int64) (*Conversation, bool) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	conversation, ok := manager.conversations[chatID]
+
+	return conversation, ok
+}
+
+func (manager *Manager) accepts(chatID int64, userID int32) (*Conversation, bool) {
+	conversation, ok := manager.Active(chatID)
+	if !ok {
+		return nil, false
+	}
+
+	return conversation, conversation.UserID == 0 || conversation.UserID == userID
+}
+
+func (manager *Manager) isCancelCommand(message *client.Message) bool {
+	cmd, ok := command.Parse(message)
+	if !ok {
+		return false
+	}
+
+	for _, name := range manager.cancelCommands {
+		if strings.EqualFold(strings.TrimPrefix(name, "/"), cmd.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HandleUpdate delivers new message or callback query to the active conversation of its chat.
+// It returns false if the update is not a reply to any conversation or the conversation is not waiting for a reply.
+func (manager *Manager) HandleUpdate(ctx context.Context, update client.Update) (bool, error) {
+	switch u := update.(type) {
+	case *client.UpdateNewMessage:
+		if u.Message == nil || u.Message.IsOutgoing {
+			return false, nil
+		}
+
+		conversation, ok := manager.accepts(u.Message.ChatID, u.Message.SenderUserID)
+		if !ok {
+			return false, nil
+		}
+
+		if manager.isCancelCommand(u.Message) {
+			conversation.Cancel()
+
+			if manager.cancelText != "" {
+				_, err := conversation.Send(manager.cancelText, nil)
+
+				return true, err
+			}
+
+			return true, nil
+		}
+
+		return conversation.deliver(&Reply{
+			Message: u.Message,
+		}), nil
+
+	case *client.UpdateNewCallbackQuery:
+		conversation, ok := manager.accepts(u.ChatID, u.SenderUserID)
+		if !ok {
+			return false, nil
+		}
+
+		query, _ := callback.NewQuery(manager.tdlibClient, u)
+
+		if !conversation.deliver(&Reply{
+			Query: query,
+		}) {
+			return false, nil
+		}
+
+		return true, query.Answer("", false)
+	}
+
+	return false, nil
+}
+
+// Mount registers the manager as a handler of replies in the update router.
+// It should be mounted before other handlers of new messages and callback queries.
+func (manager *Manager) Mount(updateRouter *router.Router) {
+	handler := func(ctx context.Context, update client.Update) error {
+		ok, err := manager.HandleUpdate(ctx, update)
+		if !ok {
+			// the question is finished after the update is routed to the manager
+			log.Printf("Reply %s is not delivered to a finished question\n", update.UpdateType())
+		}
+
+		return err
+	}
+
+	predicate := func(update client.Update) bool {
+		switch u := update.(type) {
+		case *client.UpdateNewMessage:
+			if u.Message == nil || u.Message.IsOutgoing {
+				return false
+			}
+
+			conversation, ok := manager.accepts(u.Message.ChatID, u.Message.SenderUserID)
+
+			return ok && (conversation.isWaiting() || manager.isCancelCommand(u.Message))
+
+		case *client.UpdateNewCallbackQuery:
+			conversation, ok := manager.accepts(u.ChatID, u.SenderUserID)
+
+			return ok && conversation.isWaiting()
+		}
+
+		return false
+	}
+
+	updateRouter.Handle(client.TypeUpdateNewMessage, handler, predicate)
+	updateRouter.Handle(client.TypeUpdateNewCallbackQuery, handler, predicate)
+}
@@ -0,0 +1,163 @@
+package conversation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot contains persisted state of a conversation.
+type Snapshot struct {
+	ChatID    int64             `json:"chat_id"`
+	UserID    int32             `json:"user_id"`
+	Flow      string            `json:"flow"`
+	State     string            `json:"state"`
+	Data      map[string]string `json:"data"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Store is interface declaring persistence of conversation states.
+type Store interface {
+	Save(snapshot *Snapshot) error
+	Delete(chatID int64) error
+	List() ([]*Snapshot, error)
+}
+
+// MemoryStore implements Store interface keeping snapshots in memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	snapshots map[int64]*Snapshot
+}
+
+// NewMemoryStore creates new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		snapshots: map[int64]*Snapshot{},
+	}
+}
+
+// Save stores the snapshot.
+func (store *MemoryStore) Save(snapshot *Snapshot) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.snapshots[snapshot.ChatID] = snapshot
+
+	return nil
+}
+
+// Delete removes snapshot of the chat.
+func (store *MemoryStore) Delete(chatID int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.snapshots, chatID)
+
+	return nil
+}
+
+// List returns all stored snapshots.
+func (store *MemoryStore) List() ([]*Snapshot, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	snapshots := make([]*Snapshot, 0, len(store.snapshots))
+	for _, snapshot := range store.snapshots {
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// FileStore implements Store interface keeping every snapshot in a separate JSON file of the directory.
+type FileStore struct {
+	mu        sync.Mutex
+	directory string
+}
+
+// NewFileStore creates new instance of FileStore creating the directory if it does not exist.
+func NewFileStore(directory string) (*FileStore, error) {
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		directory: directory,
+	}, nil
+}
+
+// Save stores the snapshot.
+func (store *FileStore) Save(snapshot *Snapshot) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	path := store.path(snapshot.ChatID)
+
+	err = ioutil.WriteFile(path+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// Delete removes snapshot of the chat.
+func (store *FileStore) Delete(chatID int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	err := os.Remove(store.path(chatID))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// List returns all stored snapshots.
+func (store *FileStore) List() ([]*Snapshot, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	files, err := ioutil.ReadDir(store.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(store.directory, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var snapshot Snapshot
+		err = json.Unmarshal(data, &snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
+
+func (store *FileStore) path(chatID int64) string {
+	return filepath.Join(store.directory, strconv.FormatInt(chatID, 10)+".json")
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrEmptyCredential is returned by providers which have no value.
+var ErrEmptyCredential = errors.New("credential is empty")
+
+// Provider is a function type which provides an authorization credential, e.g. phone number, code or password.
+// It receives the authorization state which waits for the credential. A callback can be used as a provider directly.
+type Provider func(state AuthorizationState) (string, error)
+
+// FromValue returns provider of the constant value.
+func FromValue(value string) Provider {
+	return func(state AuthorizationState) (string, error) {
+		if value == "" {
+			return "", ErrEmptyCredential
+		}
+
+		return value, nil
+	}
+}
+
+// FromEnv returns provider reading the value from the environment variable.
+func FromEnv(name string) Provider {
+	return func(state AuthorizationState) (string, error) {
+		value := os.Getenv(name)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is empty", name)
+		}
+
+		return value, nil
+	}
+}
+
+// FromFile returns provider reading the value from the file, e.g. a mounted secret. Surrounding spaces are trimmed.
+func FromFile(path string) Provider {
+	return func(state AuthorizationState) (string, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", fmt.Errorf("file %s is empty", path)
+		}
+
+		return value, nil
+	}
+}
+
+// FromWaitFile returns provider waiting until the file appears, reading the value from it and removing the file,
+// so a value written for one authorization is not reused by the next one. Zero timeout means waiting forever.
+func FromWaitFile(path string, timeout time.Duration) Provider {
+	return func(state AuthorizationState) (string, error) {
+		var deadline time.Time
+		if timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+
+		for {
+			data, err := ioutil.ReadFile(path)
+			if err == nil {
+				value := strings.TrimSpace(string(data))
+				if value != "" {
+					return value, os.Remove(path)
+				}
+			} else if !os.IsNotExist(err) {
+				return "", err
+			}
+
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return "", fmt.Errorf("file %s did not appear in %s", path, timeout)
+			}
+
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// FirstOf returns provider returning the value of the first provider which succeeds.
+func FirstOf(providers ...Provider) Provider {
+	return func(state AuthorizationState) (string, error) {
+		err := ErrEmptyCredential
+		for _, provider := range providers {
+			var value string
+			value, err = provider(state)
+			if err == nil {
+				return value, nil
+			}
+		}
+
+		return "", err
+	}
+}
@@ -0,0 +1,163 @@
+package download
+
+import (
+	"context"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Progress contains state of a file download.
+type Progress struct {
+	// Identifier of the file
+	FileID int32
+	// Total downloaded bytes
+	DownloadedSize int32
+	// Size of the downloaded prefix of the file which is ready to be read
+	DownloadedPrefixSize int32
+	// Size of the file; expected size if the exact size is unknown; 0 if unknown
+	TotalSize int32
+	// True, if the file is fully downloaded
+	IsCompleted bool
+}
+
+// Percent returns download progress in percents; 0 if the size of the file is unknown.
+func (progress Progress) Percent() float64 {
+	if progress.IsCompleted {
+		return 100
+	}
+
+	if progress.TotalSize <= 0 {
+		return 0
+	}
+
+	return float64(progress.DownloadedSize) * 100 / float64(progress.TotalSize)
+}
+
+func newProgress(file *client.File) Progress {
+	total := file.Size
+	if total == 0 {
+		total = file.ExpectedSize
+	}
+
+	return Progress{
+		FileID:               file.ID,
+		DownloadedSize:       file.Local.DownloadedSize,
+		DownloadedPrefixSize: file.Local.DownloadedPrefixSize,
+		TotalSize:            total,
+		IsCompleted:          file.Local.IsDownloadingCompleted,
+	}
+}
+
+// Download tracks a file download queued by Manager.
+type Download struct {
+	manager   *Manager
+	mu        sync.Mutex
+	progress  chan Progress
+	done      chan struct{}
+	callbacks []func(Progress)
+	path      string
+	err       error
+	// the following fields are guarded by the manager's lock
+	priority int32
+	started  bool
+	retrying bool
+	attempts int
+	// true if the download is seen active since the last start
+	wasActive bool
+	// true if a state of the file is seen since the last start
+	updated bool
+	// Identifier of the file
+	FileID int32
+}
+
+func newDownload(manager *Manager, fileID int32, priority int32) *Download {
+	return &Download{
+		manager:  manager,
+		progress: make(chan Progress, 1),
+		done:     make(chan struct{}),
+		priority: priority,
+		FileID:   fileID,
+	}
+}
+
+// Progress returns channel receiving the latest progress of the download. Intermediate values are dropped
+// if the channel is not read in time. The channel is closed when the download finishes.
+func (download *Download) Progress() <-chan Progress {
+	return download.progress
+}
+
+// OnProgress registers callback called on every progress change of the download.
+func (download *Download) OnProgress(callback func(Progress)) {
+	download.mu.Lock()
+	defer download.mu.Unlock()
+
+	download.callbacks = append(download.callbacks, callback)
+}
+
+// Done returns channel which is closed when the download finishes.
+func (download *Download) Done() <-chan struct{} {
+	return download.done
+}
+
+// Result returns local path of the downloaded file or error. It must be called after the download finishes.
+func (download *Download) Result() (string, error) {
+	download.mu.Lock()
+	defer download.mu.Unlock()
+
+	return download.path, download.err
+}
+
+// Wait waits for the download to finish and returns local path of the file.
+// The download is cancelled if the context is done before it finishes.
+func (download *Download) Wait(ctx context.Context) (string, error) {
+	select {
+	case <-download.done:
+		return download.Result()
+
+	case <-ctx.Done():
+		err := download.manager.Cancel(download.FileID)
+		if err != nil {
+			return "", err
+		}
+
+		return "", ctx.Err()
+	}
+}
+
+// notify delivers the progress to the channel and callbacks. Callbacks are called without the lock, so they can use the download.
+func (download *Download) notify(progress Progress) {
+	download.mu.Lock()
+
+	select {
+	case <-download.done:
+		download.mu.Unlock()
+
+		return
+	default:
+	}
+
+	callbacks := make([]func(Progress), len(download.callbacks))
+	copy(callbacks, download.callbacks)
+
+	select {
+	case <-download.progress:
+	default:
+	}
+	download.progress <- progress
+	download.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(progress)
+	}
+}
+
+func (download *Download) complete(path string, err error) {
+	download.mu.Lock()
+	defer download.mu.Unlock()
+
+	download.path = path
+	download.err = err
+	close(download.progress)
+	close(download.done)
+}
@@ -0,0 +1,291 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+var (
+	// ErrCancelled is error returned when a download is cancelled.
+	ErrCancelled = errors.New("download cancelled")
+	// ErrFailed is error returned when a download is stopped by TDLib and all retries are exhausted.
+	ErrFailed = errors.New("download failed")
+)
+
+// Manager queues file downloads, limits the number of simultaneous downloads and tracks their progress.
+// Manager must be running (see Run) or receive updates by HandleUpdate to track downloads.
+type Manager struct {
+	tdlibClient *client.Client
+	mu          sync.Mutex
+	downloads   map[int32]*Download
+	queue       []*Download
+	active      int
+	maxActive   int
+	retries     int
+	retryDelay  time.Duration
+}
+
+// Option is a function type which adjusts manager's configuration.
+type Option func(*Manager)
+
+// WithMaxActive configures the manager to download at most specified number of files simultaneously.
+func WithMaxActive(maxActive int) Option {
+	return func(manager *Manager) {
+		if maxActive > 0 {
+			manager.maxActive = maxActive
+		}
+	}
+}
+
+// WithRetries configures the manager to restart failed downloads specified number of times after the delay.
+func WithRetries(retries int, retryDelay time.Duration) Option {
+	return func(manager *Manager) {
+		manager.retries = retries
+		manager.retryDelay = retryDelay
+	}
+}
+
+// New creates new download manager.
+func New(tdlibClient *client.Client, options ...Option) *Manager {
+	manager := &Manager{
+		tdlibClient: tdlibClient,
+		downloads:   map[int32]*Download{},
+		maxActive:   4,
+		retries:     3,
+		retryDelay:  time.Second,
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager
+}
+
+// Run listens client's updates and tracks downloads until the context is done.
+func (manager *Manager) Run(ctx context.Context) error {
+	listener := manager.tdlibClient.GetListener()
+	defer listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case update, ok := <-listener.Updates:
+			if !ok {
+				return nil
+			}
+
+			if u, ok := update.(client.Update); ok {
+				manager.HandleUpdate(u)
+			}
+		}
+	}
+}
+
+// HandleUpdate tracks progress of downloads by updateFile.
+func (manager *Manager) HandleUpdate(update client.Update) {
+	updateFile, ok := update.(*client.UpdateFile)
+	if ok && updateFile.File != nil {
+		manager.update(updateFile.File)
+	}
+}
+
+// Download queues download of the file with the priority from 1 to 32. Downloads with higher priority start earlier.
+// If the file is already being downloaded the existing download is returned and its priority is raised if needed.
+func (manager *Manager) Download(fileID int32, priority int32) *Download {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	download, ok := manager.downloads[fileID]
+	if ok {
+		if priority > download.priority {
+			download.priority = priority
+			if download.started {
+				go manager.start(download)
+			}
+		}
+
+		return download
+	}
+
+	download = newDownload(manager, fileID, priority)
+	manager.downloads[fileID] = download
+	manager.queue = append(manager.queue, download)
+	manager.schedule()
+
+	return download
+}
+
+// DownloadAndWait downloads the file and returns its local path.
+// The download is cancelled if the context is done before it finishes.
+func (manager *Manager) DownloadAndWait(ctx context.Context, fileID int32, priority int32) (string, error) {
+	return manager.Download(fileID, priority).Wait(ctx)
+}
+
+// Cancel cancels download of the file.
+func (manager *Manager) Cancel(fileID int32) error {
+	manager.mu.Lock()
+	download, ok := manager.downloads[fileID]
+	if !ok {
+		manager.mu.Unlock()
+
+		return nil
+	}
+	started := download.started
+	manager.mu.Unlock()
+
+	// the download is unregistered first, so the update about the stopped download doesn't restart it
+	manager.finish(download, "", ErrCancelled)
+
+	if !started {
+		return nil
+	}
+
+	_, err := manager.tdlibClient.CancelDownloadFile(&client.CancelDownloadFileRequest{
+		FileID: fileID,
+	})
+
+	return err
+}
+
+// schedule starts queued downloads with the highest priority while there are free slots. Must be called under the lock.
+func (manager *Manager) schedule() {
+	for manager.active < manager.maxActive && len(manager.queue) > 0 {
+		next := 0
+		for i, download := range manager.queue {
+			if download.priority > manager.queue[next].priority {
+				next = i
+			}
+		}
+
+		download := manager.queue[next]
+		manager.queue = append(manager.queue[:next], manager.queue[next+1:]...)
+		manager.active++
+		download.started = true
+
+		go manager.start(download)
+	}
+}
+
+// start starts or restarts the download in TDLib unless it is already finished or cancelled.
+func (manager *Manager) start(download *Download) {
+	manager.mu.Lock()
+	if manager.downloads[download.FileID] != download {
+		manager.mu.Unlock()
+
+		return
+	}
+	priority := download.priority
+	download.wasActive = false
+	download.updated = false
+	manager.mu.Unlock()
+
+	file, err := manager.tdlibClient.DownloadFile(&client.DownloadFileRequest{
+		FileID:   download.FileID,
+		Priority: priority,
+	})
+	if err != nil {
+		manager.retry(download, err)
+
+		return
+	}
+
+	manager.update(file)
+}
+
+// update applies new state of the file to its download. A stopped download is counted as failed
+// only if it is seen active or it is not the first state since the start, as the first state can precede the download.
+func (manager *Manager) update(file *client.File) {
+	if file.Local == nil {
+		return
+	}
+
+	manager.mu.Lock()
+	download, ok := manager.downloads[file.ID]
+	started := ok && download.started
+	var failed bool
+	if started {
+		failed = !file.Local.IsDownloadingActive && (download.wasActive || download.updated)
+		download.updated = true
+		if file.Local.IsDownloadingActive {
+			download.wasActive = true
+		}
+	}
+	manager.mu.Unlock()
+
+	if !started {
+		return
+	}
+
+	download.notify(newProgress(file))
+
+	switch {
+	case file.Local.IsDownloadingCompleted:
+		manager.finish(download, file.Local.Path, nil)
+
+	case failed:
+		manager.retry(download, ErrFailed)
+	}
+}
+
+func (manager *Manager) retry(download *Download, err error) {
+	manager.mu.Lock()
+	if manager.downloads[download.FileID] != download || download.retrying {
+		manager.mu.Unlock()
+
+		return
+	}
+
+	if download.attempts >= manager.retries {
+		manager.mu.Unlock()
+		manager.finish(download, "", err)
+
+		return
+	}
+
+	download.attempts++
+	download.retrying = true
+	manager.mu.Unlock()
+
+	time.AfterFunc(manager.retryDelay, func() {
+		manager.mu.Lock()
+		download.retrying = false
+		registered := manager.downloads[download.FileID] == download
+		manager.mu.Unlock()
+
+		if registered {
+			manager.start(download)
+		}
+	})
+}
+
+func (manager *Manager) finish(download *Download, path string, err error) {
+	manager.mu.Lock()
+	if manager.downloads[download.FileID] != download {
+		manager.mu.Unlock()
+
+		return
+	}
+
+	delete(manager.downloads, download.FileID)
+	if download.started {
+		manager.active--
+	} else {
+		for i, queued := range manager.queue {
+			if queued == download {
+				manager.queue = append(manager.queue[:i], manager.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	manager.schedule()
+	manager.mu.Unlock()
+
+	download.complete(path, err)
+}
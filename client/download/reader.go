@@ -0,0 +1,305 @@
+package download
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+var (
+	// ErrReaderClosed is error returned when reading from the closed reader.
+	ErrReaderClosed = errors.New("reader is closed")
+	// ErrUnknownSize is error returned when seeking relative to the end of a file with unknown size.
+	ErrUnknownSize = errors.New("file size is unknown")
+	// ErrInvalidOffset is error returned when seeking before the start of a file.
+	ErrInvalidOffset = errors.New("invalid offset")
+)
+
+// Reader reads a file while it is being downloaded. Reads block until the requested part of the file is downloaded.
+// Reader implements io.ReadSeeker and can be used with http.ServeContent.
+type Reader struct {
+	manager   *Manager
+	download  *Download
+	fileID    int32
+	mu        sync.Mutex
+	changed   chan struct{}
+	file      *os.File
+	path      string
+	size      int64
+	prefix    int64
+	offset    int64
+	completed bool
+	closed    bool
+}
+
+// Open starts download of the file with the priority from 1 to 32 and returns reader of the file.
+// Closing the reader doesn't cancel the download.
+func (manager *Manager) Open(fileID int32, priority int32) (*Reader, error) {
+	file, err := manager.tdlibClient.GetFile(&client.GetFileRequest{
+		FileID: fileID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{
+		manager: manager,
+		fileID:  fileID,
+		changed: make(chan struct{}, 1),
+		size:    int64(file.Size),
+	}
+
+	if file.Local != nil {
+		reader.path = file.Local.Path
+		reader.prefix = int64(file.Local.DownloadedPrefixSize)
+		reader.completed = file.Local.IsDownloadingCompleted
+	}
+
+	if !reader.completed {
+		reader.download = manager.Download(fileID, priority)
+		reader.download.OnProgress(reader.progress)
+	}
+
+	return reader, nil
+}
+
+// Size returns size of the file; 0 if unknown.
+func (reader *Reader) Size() int64 {
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	return reader.size
+}
+
+// Read reads up to len(data) bytes from the file. It blocks until at least one byte at the current offset is downloaded.
+func (reader *Reader) Read(data []byte) (int, error) {
+	for {
+		reader.mu.Lock()
+		if reader.closed {
+			reader.mu.Unlock()
+
+			return 0, ErrReaderClosed
+		}
+
+		offset := reader.offset
+		available := reader.prefix
+		if reader.completed && reader.size > 0 {
+			available = reader.size
+		}
+
+		if offset < available {
+			if int64(len(data)) > available-offset {
+				data = data[:available-offset]
+			}
+			reader.mu.Unlock()
+
+			n, err := reader.readAt(data, offset)
+
+			reader.mu.Lock()
+			reader.offset += int64(n)
+			reader.mu.Unlock()
+
+			return n, err
+		}
+
+		if reader.completed {
+			reader.mu.Unlock()
+
+			return 0, io.EOF
+		}
+		reader.mu.Unlock()
+
+		err := reader.wait()
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Seek sets the offset for the next Read. Offset relative to the end requires known size of the file.
+func (reader *Reader) Seek(offset int64, whence int) (int64, error) {
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+
+	case io.SeekCurrent:
+		offset += reader.offset
+
+	case io.SeekEnd:
+		if reader.size <= 0 {
+			return 0, ErrUnknownSize
+		}
+		offset += reader.size
+
+	default:
+		return 0, ErrInvalidOffset
+	}
+
+	if offset < 0 {
+		return 0, ErrInvalidOffset
+	}
+
+	reader.offset = offset
+
+	return offset, nil
+}
+
+// Close closes the reader and unblocks pending reads.
+func (reader *Reader) Close() error {
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	if reader.closed {
+		return nil
+	}
+	reader.closed = true
+	reader.signal()
+
+	if reader.file != nil {
+		return reader.file.Close()
+	}
+
+	return nil
+}
+
+// readAt reads from the downloaded part of the file at the offset.
+// The lock is not held while the path is resolved and the file is read, so progress and Close are not blocked.
+func (reader *Reader) readAt(data []byte, offset int64) (int, error) {
+	file, err := reader.open()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := file.ReadAt(data, offset)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+
+	if err != nil && reader.isClosed() {
+		return n, ErrReaderClosed
+	}
+
+	return n, err
+}
+
+// open returns the opened file, opening it on the first call.
+func (reader *Reader) open() (*os.File, error) {
+	reader.mu.Lock()
+	closed := reader.closed
+	file := reader.file
+	path := reader.path
+	reader.mu.Unlock()
+
+	if closed {
+		return nil, ErrReaderClosed
+	}
+
+	if file != nil {
+		return file, nil
+	}
+
+	if path == "" {
+		tdlibFile, err := reader.manager.tdlibClient.GetFile(&client.GetFileRequest{
+			FileID: reader.fileID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if tdlibFile.Local == nil || tdlibFile.Local.Path == "" {
+			return nil, ErrFailed
+		}
+		path = tdlibFile.Local.Path
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	// the reader is closed or the file is opened by a concurrent read meanwhile
+	if reader.closed {
+		file.Close()
+
+		return nil, ErrReaderClosed
+	}
+
+	if reader.file != nil {
+		file.Close()
+
+		return reader.file, nil
+	}
+
+	if reader.path == "" {
+		reader.path = path
+	}
+	reader.file = file
+
+	return file, nil
+}
+
+// isClosed returns true if the reader is closed.
+func (reader *Reader) isClosed() bool {
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	return reader.closed
+}
+
+// wait waits for the next progress of the download.
+func (reader *Reader) wait() error {
+	select {
+	case <-reader.changed:
+		return nil
+
+	case <-reader.download.Done():
+		path, err := reader.download.Result()
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		reader.mu.Lock()
+		defer reader.mu.Unlock()
+
+		reader.path = path
+		reader.completed = true
+		if reader.size == 0 {
+			reader.size = info.Size()
+		}
+
+		return nil
+	}
+}
+
+func (reader *Reader) progress(progress Progress) {
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+
+	reader.prefix = int64(progress.DownloadedPrefixSize)
+	reader.completed = progress.IsCompleted
+	if progress.IsCompleted && reader.size == 0 {
+		reader.size = int64(progress.TotalSize)
+	}
+	reader.signal()
+}
+
+// signal wakes up pending read. Must be called under the lock.
+func (reader *Reader) signal() {
+	select {
+	case reader.changed <- struct{}{}:
+	default:
+	}
+}
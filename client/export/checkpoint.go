@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// Checkpoint contains progress of a chat export which allows to resume an interrupted export.
+type Checkpoint struct {
+	ChatID int64 `json:"chat_id"`
+	// Identifier of the last exported message; messages are exported from the newest to the oldest
+	LastMessageID int64 `json:"last_message_id"`
+	// Size of the exported records in the spool file
+	Offset    int64     `json:"offset"`
+	Exported  int       `json:"exported"`
+	Completed bool      `json:"completed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	err = json.Unmarshal(data, &checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+func saveCheckpoint(path string, checkpoint *Checkpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,423 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/download"
+	"github.com/u-robot/go-tdlib/client/markup"
+	"github.com/u-robot/go-tdlib/client/puller"
+)
+
+// Format is a format of exported chat history.
+type Format int
+
+const (
+	// FormatNDJSON writes every message as a JSON object on a separate line
+	FormatNDJSON Format = iota
+	// FormatJSON writes JSON document with chat information and messages in chronological order
+	FormatJSON
+	// FormatHTML writes static HTML page with messages in chronological order
+	FormatHTML
+)
+
+// Chat contains information about an exported chat.
+type Chat struct {
+	ID    int64  `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+}
+
+// Document is a structured JSON document of exported chat history.
+type Document struct {
+	Chat       *Chat     `json:"chat"`
+	ExportedAt time.Time `json:"exported_at"`
+	Messages   []*Record `json:"messages"`
+}
+
+// Exporter exports chat history to files. Progress of every export is saved to a checkpoint file
+// next to the output file, so an interrupted export is resumed by the next export to the same path.
+type Exporter struct {
+	tdlibClient     *client.Client
+	format          Format
+	mediaDirectory  string
+	downloads       *download.Manager
+	checkpointEvery int
+	summarizer      *markup.Summarizer
+	users           map[int32]*Sender
+}
+
+// Option is a function type which adjusts exporter's configuration.
+type Option func(*Exporter)
+
+// WithFormat configures the exporter to write chat history in specified format. NDJSON is used by default.
+func WithFormat(format Format) Option {
+	return func(exporter *Exporter) {
+		exporter.format = format
+	}
+}
+
+// WithMedia configures the exporter to download attached media into the directory using the running download manager.
+func WithMedia(directory string, downloads *download.Manager) Option {
+	return func(exporter *Exporter) {
+		exporter.mediaDirectory = directory
+		exporter.downloads = downloads
+	}
+}
+
+// WithCheckpointEvery configures the exporter to save checkpoint after every specified number of messages.
+func WithCheckpointEvery(messages int) Option {
+	return func(exporter *Exporter) {
+		if messages > 0 {
+			exporter.checkpointEvery = messages
+		}
+	}
+}
+
+// WithSummarizer configures the exporter to summarize message contents with the summarizer.
+func WithSummarizer(summarizer *markup.Summarizer) Option {
+	return func(exporter *Exporter) {
+		exporter.summarizer = summarizer
+	}
+}
+
+// New creates new chat history exporter.
+func New(tdlibClient *client.Client, options ...Option) *Exporter {
+	exporter := &Exporter{
+		tdlibClient:     tdlibClient,
+		checkpointEvery: 100,
+		users:           map[int32]*Sender{},
+	}
+
+	for _, option := range options {
+		option(exporter)
+	}
+
+	if exporter.summarizer == nil {
+		exporter.summarizer = markup.NewSummarizer()
+	}
+
+	return exporter
+}
+
+// Export exports history of the chat to the file. If the context is done, the export is interrupted
+// and can be resumed by calling Export with the same chat and path.
+func (exporter *Exporter) Export(ctx context.Context, chatID int64, path string) error {
+	checkpointPath := path + ".checkpoint"
+
+	checkpoint, err := loadCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint == nil || checkpoint.ChatID != chatID || checkpoint.Completed {
+		checkpoint = &Checkpoint{
+			ChatID: chatID,
+		}
+	}
+
+	spoolPath := path
+	if exporter.format != FormatNDJSON {
+		spoolPath = path + ".part"
+	}
+
+	err = exporter.spool(ctx, spoolPath, checkpointPath, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if exporter.format != FormatNDJSON {
+		err = exporter.render(chatID, spoolPath, path)
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(spoolPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	checkpoint.Completed = true
+
+	return saveCheckpoint(checkpointPath, checkpoint)
+}
+
+// spool writes exported messages as NDJSON starting after the checkpoint.
+func (exporter *Exporter) spool(ctx context.Context, spoolPath string, checkpointPath string, checkpoint *Checkpoint) error {
+	file, err := os.OpenFile(spoolPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// drop records written after the last saved checkpoint
+	err = file.Truncate(checkpoint.Offset)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(checkpoint.Offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	save := func() error {
+		err := writer.Flush()
+		if err != nil {
+			return err
+		}
+
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		checkpoint.Offset = offset
+
+		return saveCheckpoint(checkpointPath, checkpoint)
+	}
+
+	messagePuller := puller.ChatHistoryFrom(ctx, exporter.tdlibClient, puller.HistoryCursor{
+		ChatID:        checkpoint.ChatID,
+		FromMessageID: checkpoint.LastMessageID,
+	})
+	defer messagePuller.Close()
+
+	pending := 0
+	for {
+		select {
+		case <-ctx.Done():
+			err := save()
+			if err != nil {
+				return err
+			}
+
+			return ctx.Err()
+
+		case message, ok := <-messagePuller.Messages():
+			if !ok {
+				err := messagePuller.Err()
+				if err != nil {
+					return err
+				}
+
+				return save()
+			}
+
+			record, err := exporter.record(ctx, message)
+			if err != nil {
+				return err
+			}
+
+			err = encoder.Encode(record)
+			if err != nil {
+				return err
+			}
+
+			checkpoint.LastMessageID = message.ID
+			checkpoint.Exported++
+
+			pending++
+			if pending >= exporter.checkpointEvery {
+				pending = 0
+
+				err = save()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// record converts the message into the record filling its sender and downloading its media.
+func (exporter *Exporter) record(ctx context.Context, message *client.Message) (*Record, error) {
+	record, err := newRecord(message, exporter.summarizer)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderUserID != 0 {
+		sender, ok := exporter.users[message.SenderUserID]
+		if !ok {
+			user, err := exporter.tdlibClient.GetUser(&client.GetUserRequest{
+				UserID: message.SenderUserID,
+			})
+			if err != nil {
+				sender = &Sender{
+					UserID: message.SenderUserID,
+				}
+			} else {
+				sender = newSender(user)
+			}
+			exporter.users[message.SenderUserID] = sender
+		}
+		record.Sender = sender
+	}
+
+	if record.Media != nil && exporter.downloads != nil {
+		mediaPath, err := exporter.downloadMedia(ctx, record.Media)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			log.Printf("download media of message %d error: %s\n", message.ID, err)
+		}
+		record.Media.Path = mediaPath
+	}
+
+	return record, nil
+}
+
+// downloadMedia downloads the file and copies it into the media directory. It returns path relative to the directory.
+func (exporter *Exporter) downloadMedia(ctx context.Context, media *Media) (string, error) {
+	localPath, err := exporter.downloads.DownloadAndWait(ctx, media.FileID, 1)
+	if err != nil {
+		return "", err
+	}
+
+	name := media.FileName
+	if name == "" {
+		name = media.Kind + filepath.Ext(localPath)
+	}
+	name = strconv.FormatInt(int64(media.FileID), 10) + "_" + filepath.Base(name)
+
+	err = os.MkdirAll(exporter.mediaDirectory, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = copyFile(localPath, filepath.Join(exporter.mediaDirectory, name))
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// render converts the spooled NDJSON into the document of the configured format.
+func (exporter *Exporter) render(chatID int64, spoolPath string, path string) error {
+	records, err := readRecords(spoolPath)
+	if err != nil {
+		return err
+	}
+
+	// messages are pulled from the newest to the oldest
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+
+	document := &Document{
+		Chat: &Chat{
+			ID: chatID,
+		},
+		ExportedAt: time.Now().UTC(),
+		Messages:   records,
+	}
+
+	chat, err := exporter.tdlibClient.GetChat(&client.GetChatRequest{
+		ChatID: chatID,
+	})
+	if err == nil {
+		document.Chat.Title = chat.Title
+		if chat.Type != nil {
+			document.Chat.Type = chat.Type.ChatTypeType()
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	switch exporter.format {
+	case FormatHTML:
+		mediaPrefix := ""
+		if exporter.mediaDirectory != "" {
+			mediaPrefix, err = filepath.Rel(filepath.Dir(path), exporter.mediaDirectory)
+			if err != nil {
+				mediaPrefix = exporter.mediaDirectory
+			}
+		}
+
+		err = writeHTML(writer, document, mediaPrefix)
+
+	default:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(document)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func readRecords(path string) ([]*Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []*Record
+
+	decoder := json.NewDecoder(file)
+	for {
+		var record Record
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
+
+func copyFile(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"path"
+	"path/filepath"
+)
+
+var htmlTemplate = template.Must(template.New("archive").Funcs(template.FuncMap{
+	"date": func(record *Record) string {
+		return record.Date.Format("2006-01-02 15:04:05")
+	},
+	"safe": func(text string) template.HTML {
+		// text is rendered by markup.RenderHTML which escapes it
+		return template.HTML(text)
+	},
+	"mediaPath": func(media *Media) string {
+		return media.Path
+	},
+	"isImage": func(media *Media) bool {
+		return media.Kind == "photo" || media.Kind == "sticker"
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Document.Chat.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 800px; margin: 0 auto; padding: 16px; background: #f5f5f5; }
+.message { background: #fff; border-radius: 6px; margin: 8px 0; padding: 8px 12px; }
+.message.outgoing { background: #effdde; }
+.meta { color: #888; font-size: 12px; }
+.sender { font-weight: bold; }
+.reply, .forward { border-left: 2px solid #4a90d9; padding-left: 6px; color: #4a90d9; font-size: 13px; }
+.text { white-space: pre-wrap; }
+.media img { max-width: 100%; }
+pre { background: #f0f0f0; padding: 6px; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Document.Chat.Title}}</h1>
+<p class="meta">Exported at {{.Document.ExportedAt.Format "2006-01-02 15:04:05 MST"}}, {{len .Document.Messages}} messages</p>
+{{range .Document.Messages}}
+<div class="message{{if .IsOutgoing}} outgoing{{end}}" id="message-{{.ID}}">
+<div class="meta">
+{{if .Sender}}<span class="sender">{{.Sender.Name}}</span>{{else if .AuthorSignature}}<span class="sender">{{.AuthorSignature}}</span>{{end}}
+<a href="#message-{{.ID}}">{{date .}}</a>{{if .EditDate}} (edited){{end}}
+</div>
+{{if .Forward}}<div class="forward">Forwarded{{if .Forward.AuthorSignature}} from {{.Forward.AuthorSignature}}{{end}}</div>{{end}}
+{{if .ReplyToMessageID}}<div class="reply"><a href="#message-{{.ReplyToMessageID}}">In reply to message {{.ReplyToMessageID}}</a></div>{{end}}
+{{if .Media}}{{if .Media.Path}}<div class="media">{{$href := mediaPath .Media}}{{if isImage .Media}}<a href="{{$href}}"><img src="{{$href}}" alt="{{.Media.Kind}}"></a>{{else}}<a href="{{$href}}">{{if .Media.FileName}}{{.Media.FileName}}{{else}}{{.Media.Kind}}{{end}}</a>{{end}}</div>{{end}}{{end}}
+{{if .HTML}}<div class="text">{{safe .HTML}}</div>{{else}}<div class="text">{{.Summary}}</div>{{end}}
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// writeHTML writes the document as static HTML page. Media paths are prefixed with mediaPrefix.
+func writeHTML(writer io.Writer, document *Document, mediaPrefix string) error {
+	tmpl, err := htmlTemplate.Clone()
+	if err != nil {
+		return err
+	}
+
+	tmpl.Funcs(template.FuncMap{
+		"mediaPath": func(media *Media) string {
+			return path.Join(filepath.ToSlash(mediaPrefix), media.Path)
+		},
+	})
+
+	return tmpl.Execute(writer, map[string]interface{}{
+		"Document": document,
+	})
+}
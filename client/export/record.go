@@ -0,0 +1,204 @@
+package export
+
+import (
+	"strings"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/markup"
+)
+
+// Sender contains information about the sender of an exported message.
+type Sender struct {
+	UserID   int32  `json:"user_id"`
+	Name     string `json:"name"`
+	Username string `json:"username,omitempty"`
+}
+
+// Forward contains information about the origin of a forwarded message.
+type Forward struct {
+	UserID          int32     `json:"user_id,omitempty"`
+	ChatID          int64     `json:"chat_id,omitempty"`
+	MessageID       int64     `json:"message_id,omitempty"`
+	AuthorSignature string    `json:"author_signature,omitempty"`
+	Date            time.Time `json:"date"`
+}
+
+// Media contains information about a file attached to an exported message.
+type Media struct {
+	FileID   int32  `json:"file_id"`
+	Kind     string `json:"kind"`
+	FileName string `json:"file_name,omitempty"`
+	Size     int32  `json:"size"`
+	// Path to the downloaded file relative to the media directory; empty if media is not downloaded
+	Path string `json:"path,omitempty"`
+}
+
+// Record contains exported message.
+type Record struct {
+	ID               int64                `json:"id"`
+	ChatID           int64                `json:"chat_id"`
+	Date             time.Time            `json:"date"`
+	EditDate         *time.Time           `json:"edit_date,omitempty"`
+	Sender           *Sender              `json:"sender,omitempty"`
+	AuthorSignature  string               `json:"author_signature,omitempty"`
+	IsOutgoing       bool                 `json:"is_outgoing"`
+	IsChannelPost    bool                 `json:"is_channel_post"`
+	ReplyToMessageID int64                `json:"reply_to_message_id,omitempty"`
+	Forward          *Forward             `json:"forward,omitempty"`
+	ContentType      string               `json:"content_type"`
+	Text             string               `json:"text,omitempty"`
+	Entities         []*client.TextEntity `json:"entities,omitempty"`
+	HTML             string               `json:"html,omitempty"`
+	Summary          string               `json:"summary"`
+	Media            *Media               `json:"media,omitempty"`
+}
+
+// newRecord converts the message into the record. Sender and media path are filled by the exporter.
+func newRecord(message *client.Message, summarizer *markup.Summarizer) (*Record, error) {
+	record := &Record{
+		ID:               message.ID,
+		ChatID:           message.ChatID,
+		Date:             time.Unix(int64(message.Date), 0).UTC(),
+		AuthorSignature:  message.AuthorSignature,
+		IsOutgoing:       message.IsOutgoing,
+		IsChannelPost:    message.IsChannelPost,
+		ReplyToMessageID: message.ReplyToMessageID,
+		Media:            messageMedia(message.Content),
+	}
+
+	if message.EditDate != 0 {
+		editDate := time.Unix(int64(message.EditDate), 0).UTC()
+		record.EditDate = &editDate
+	}
+
+	switch forward := message.ForwardInfo.(type) {
+	case *client.MessageForwardedFromUser:
+		record.Forward = &Forward{
+			UserID:    forward.SenderUserID,
+			ChatID:    forward.ForwardedFromChatID,
+			MessageID: forward.ForwardedFromMessageID,
+			Date:      time.Unix(int64(forward.Date), 0).UTC(),
+		}
+
+	case *client.MessageForwardedPost:
+		record.Forward = &Forward{
+			ChatID:          forward.ChatID,
+			MessageID:       forward.MessageID,
+			AuthorSignature: forward.AuthorSignature,
+			Date:            time.Unix(int64(forward.Date), 0).UTC(),
+		}
+	}
+
+	if message.Content != nil {
+		record.ContentType = message.Content.MessageContentType()
+	}
+
+	text := messageText(message.Content)
+	if text != nil {
+		record.Text = text.Text
+		record.Entities = text.Entities
+		record.HTML = markup.RenderHTML(text)
+	}
+
+	summary, err := summarizer.Summarize(message.Content)
+	if err != nil {
+		return nil, err
+	}
+	record.Summary = summary
+
+	return record, nil
+}
+
+func newSender(user *client.User) *Sender {
+	return &Sender{
+		UserID:   user.ID,
+		Name:     strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Username: user.Username,
+	}
+}
+
+// messageText returns text or caption of the message content; nil if there is no text.
+func messageText(content client.MessageContent) *client.FormattedText {
+	var text *client.FormattedText
+
+	switch c := content.(type) {
+	case *client.MessageText:
+		text = c.Text
+	case *client.MessagePhoto:
+		text = c.Caption
+	case *client.MessageDocument:
+		text = c.Caption
+	case *client.MessageVideo:
+		text = c.Caption
+	case *client.MessageAudio:
+		text = c.Caption
+	case *client.MessageAnimation:
+		text = c.Caption
+	case *client.MessageVoiceNote:
+		text = c.Caption
+	}
+
+	if text == nil || text.Text == "" {
+		return nil
+	}
+
+	return text
+}
+
+// messageMedia returns the main file attached to the message content; nil if there is no file.
+func messageMedia(content client.MessageContent) *Media {
+	var kind, fileName string
+	var file *client.File
+
+	switch c := content.(type) {
+	case *client.MessagePhoto:
+		if c.Photo != nil && len(c.Photo.Sizes) > 0 {
+			kind, file = "photo", c.Photo.Sizes[len(c.Photo.Sizes)-1].Photo
+		}
+	case *client.MessageDocument:
+		if c.Document != nil {
+			kind, fileName, file = "document", c.Document.FileName, c.Document.Document
+		}
+	case *client.MessageVideo:
+		if c.Video != nil {
+			kind, fileName, file = "video", c.Video.FileName, c.Video.Video
+		}
+	case *client.MessageAudio:
+		if c.Audio != nil {
+			kind, fileName, file = "audio", c.Audio.FileName, c.Audio.Audio
+		}
+	case *client.MessageAnimation:
+		if c.Animation != nil {
+			kind, fileName, file = "animation", c.Animation.FileName, c.Animation.Animation
+		}
+	case *client.MessageVoiceNote:
+		if c.VoiceNote != nil {
+			kind, file = "voice_note", c.VoiceNote.Voice
+		}
+	case *client.MessageVideoNote:
+		if c.VideoNote != nil {
+			kind, file = "video_note", c.VideoNote.Video
+		}
+	case *client.MessageSticker:
+		if c.Sticker != nil {
+			kind, file = "sticker", c.Sticker.Sticker
+		}
+	}
+
+	if file == nil {
+		return nil
+	}
+
+	size := file.Size
+	if size == 0 {
+		size = file.ExpectedSize
+	}
+
+	return &Media{
+		FileID:   file.ID,
+		Kind:     kind,
+		FileName: fileName,
+		Size:     size,
+	}
+}
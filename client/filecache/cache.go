@@ -0,0 +1,271 @@
+package filecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Cache maps content hashes of local files to remote identifiers of already uploaded files,
+// so identical files are sent by InputFileRemote instead of being uploaded again.
+type Cache struct {
+	tdlibClient *client.Client
+	store       Store
+}
+
+// Option is a function type which adjusts cache's configuration.
+type Option func(*Cache)
+
+// WithStore configures the cache to persist remote identifiers in the store. Memory store is used by default.
+func WithStore(store Store) Option {
+	return func(cache *Cache) {
+		cache.store = store
+	}
+}
+
+// New creates new remote file identifier cache.
+func New(tdlibClient *client.Client, options ...Option) *Cache {
+	cache := &Cache{
+		tdlibClient: tdlibClient,
+		store:       NewMemoryStore(),
+	}
+
+	for _, option := range options {
+		option(cache)
+	}
+
+	return cache
+}
+
+// Key returns cache key of the local file sent as the kind of content, e.g. as photo or as document.
+func Key(kind string, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return kind + ":" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Lookup returns remote identifier of the file stored by the key.
+func (cache *Cache) Lookup(key string) (string, bool, error) {
+	entry, err := cache.store.Get(key)
+	if err != nil || entry == nil {
+		return "", false, err
+	}
+
+	return entry.RemoteID, true, nil
+}
+
+// Remember stores remote identifier of the uploaded file by the key.
+// Files which are not uploaded yet are ignored.
+func (cache *Cache) Remember(key string, file *client.File) error {
+	if file == nil || file.Remote == nil || !file.Remote.IsUploadingCompleted || file.Remote.ID == "" {
+		return nil
+	}
+
+	return cache.store.Save(&Entry{
+		Key:       key,
+		RemoteID:  file.Remote.ID,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// Invalidate removes remote identifier stored by the key.
+func (cache *Cache) Invalidate(key string) error {
+	return cache.store.Delete(key)
+}
+
+// Prepare replaces local file of the content with remote one if the file has already been uploaded.
+// It returns the content to send, cache key of the file and whether the remote file is used.
+// Contents without local files are returned as is with empty key.
+func (cache *Cache) Prepare(content client.InputMessageContent) (client.InputMessageContent, string, bool, error) {
+	inputFile, replace := contentFile(content)
+	local, ok := inputFile.(*client.InputFileLocal)
+	if !ok {
+		return content, "", false, nil
+	}
+
+	key, err := Key(content.InputMessageContentType(), local.Path)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	remoteID, ok, err := cache.Lookup(key)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if !ok {
+		return content, key, false, nil
+	}
+
+	return replace(&client.InputFileRemote{
+		ID: remoteID,
+	}), key, true, nil
+}
+
+// SendMessage sends a message reusing remote files and waits until it is sent to the server.
+// If TDLib rejects the cached remote identifier, the entry is invalidated and the local file is sent again.
+func (cache *Cache) SendMessage(ctx context.Context, request *client.SendMessageRequest) (*client.Message, error) {
+	content, key, cached, err := cache.Prepare(request.InputMessageContent)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := *request
+	prepared.InputMessageContent = content
+
+	message, err := cache.tdlibClient.SendMessageAndWait(ctx, &prepared)
+	if err != nil && cached && isInvalidRemoteFile(err) {
+		err = cache.Invalidate(key)
+		if err != nil {
+			return nil, err
+		}
+
+		cached = false
+		message, err = cache.tdlibClient.SendMessageAndWait(ctx, request)
+	}
+	if err != nil {
+		return message, err
+	}
+
+	if key != "" && !cached {
+		err = cache.Remember(key, messageFile(message.Content))
+		if err != nil {
+			return message, err
+		}
+	}
+
+	return message, nil
+}
+
+// isInvalidRemoteFile returns true if the error reports invalid remote file identifier.
+func isInvalidRemoteFile(err error) bool {
+	var tdlibErr *client.Error
+	switch e := err.(type) {
+	case client.ResponseError:
+		tdlibErr = e.Err
+	case client.SendMessageError:
+		tdlibErr = e.Err
+	}
+
+	if tdlibErr == nil {
+		return false
+	}
+
+	message := strings.ToLower(tdlibErr.Message)
+
+	return strings.Contains(message, "remote file identifier") ||
+		strings.Contains(message, "file_id_invalid") ||
+		strings.Contains(message, "file_reference")
+}
+
+// contentFile returns input file of the content and function creating copy of the content with another input file.
+func contentFile(content client.InputMessageContent) (client.InputFile, func(client.InputFile) client.InputMessageContent) {
+	switch c := content.(type) {
+	case *client.InputMessagePhoto:
+		return c.Photo, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.Photo = file
+			return &copied
+		}
+	case *client.InputMessageDocument:
+		return c.Document, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.Document = file
+			return &copied
+		}
+	case *client.InputMessageVideo:
+		return c.Video, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.Video = file
+			return &copied
+		}
+	case *client.InputMessageAudio:
+		return c.Audio, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.Audio = file
+			return &copied
+		}
+	case *client.InputMessageAnimation:
+		return c.Animation, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.Animation = file
+			return &copied
+		}
+	case *client.InputMessageVoiceNote:
+		return c.VoiceNote, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.VoiceNote = file
+			return &copied
+		}
+	case *client.InputMessageVideoNote:
+		return c.VideoNote, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.VideoNote = file
+			return &copied
+		}
+	case *client.InputMessageSticker:
+		return c.Sticker, func(file client.InputFile) client.InputMessageContent {
+			copied := *c
+			copied.Sticker = file
+			return &copied
+		}
+	}
+
+	return nil, nil
+}
+
+// messageFile returns the main file of the message content.
+func messageFile(content client.MessageContent) *client.File {
+	switch c := content.(type) {
+	case *client.MessagePhoto:
+		if c.Photo != nil && len(c.Photo.Sizes) > 0 {
+			return c.Photo.Sizes[len(c.Photo.Sizes)-1].Photo
+		}
+	case *client.MessageDocument:
+		if c.Document != nil {
+			return c.Document.Document
+		}
+	case *client.MessageVideo:
+		if c.Video != nil {
+			return c.Video.Video
+		}
+	case *client.MessageAudio:
+		if c.Audio != nil {
+			return c.Audio.Audio
+		}
+	case *client.MessageAnimation:
+		if c.Animation != nil {
+			return c.Animation.Animation
+		}
+	case *client.MessageVoiceNote:
+		if c.VoiceNote != nil {
+			return c.VoiceNote.Voice
+		}
+	case *client.MessageVideoNote:
+		if c.VideoNote != nil {
+			return c.VideoNote.Video
+		}
+	case *client.MessageSticker:
+		if c.Sticker != nil {
+			return c.Sticker.Sticker
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package filecache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry contains remote identifier of an uploaded file.
+type Entry struct {
+	Key       string    `json:"key"`
+	RemoteID  string    `json:"remote_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store is interface declaring persistence of remote file identifiers.
+type Store interface {
+	Get(key string) (*Entry, error)
+	Save(entry *Entry) error
+	Delete(key string) error
+}
+
+// MemoryStore implements Store interface keeping entries in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// NewMemoryStore creates new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*Entry{},
+	}
+}
+
+// Get returns entry by the key; nil if there is no entry.
+func (store *MemoryStore) Get(key string) (*Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.entries[key], nil
+}
+
+// Save stores the entry.
+func (store *MemoryStore) Save(entry *Entry) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.entries[entry.Key] = entry
+
+	return nil
+}
+
+// Delete removes entry by the key.
+func (store *MemoryStore) Delete(key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.entries, key)
+
+	return nil
+}
+
+// FileStore implements Store interface keeping all entries in memory and in a JSON file.
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*Entry
+}
+
+// NewFileStore creates new instance of FileStore loading entries from the file if it exists.
+func NewFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		path:    path,
+		entries: map[string]*Entry{},
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		var entries []*Entry
+		err = json.Unmarshal(data, &entries)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			store.entries[entry.Key] = entry
+		}
+	}
+
+	return store, nil
+}
+
+// Get returns entry by the key; nil if there is no entry.
+func (store *FileStore) Get(key string) (*Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.entries[key], nil
+}
+
+// Save stores the entry.
+func (store *FileStore) Save(entry *Entry) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.entries[entry.Key] = entry
+
+	return store.flush()
+}
+
+// Delete removes entry by the key.
+func (store *FileStore) Delete(key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.entries[key]; !ok {
+		return nil
+	}
+	delete(store.entries, key)
+
+	return store.flush()
+}
+
+// flush writes all entries to the file. Must be called under the lock.
+func (store *FileStore) flush() error {
+	entries := make([]*Entry, 0, len(store.entries))
+	for _, entry := range store.entries {
+		entries = append(entries, entry)
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(store.path+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(store.path+".tmp", store.path)
+}
@@ -0,0 +1,127 @@
+package generation
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// URLConversion is conversion requested for files which must be downloaded from the HTTP/HTTPS URL in OriginalPath.
+const URLConversion = "#url#"
+
+const (
+	// progressInterval is the minimal interval between progress reports of a writer
+	progressInterval = 500 * time.Millisecond
+	// progressBytes is the number of written bytes which is reported regardless of the interval
+	progressBytes = 1 << 20
+)
+
+// Generation contains parameters of a file generation requested by TDLib.
+type Generation struct {
+	tdlibClient *client.Client
+	// Unique identifier for the generation process
+	ID client.Int64JSON
+	// The path to a file from which a new file is generated; may be empty
+	OriginalPath string
+	// The path to a file that should be created and where the new file should be generated
+	DestinationPath string
+	// String specifying the conversion applied to the original file
+	Conversion string
+}
+
+// SetProgress informs TDLib about the number of bytes already generated.
+func (generation *Generation) SetProgress(expectedSize int32, localPrefixSize int32) error {
+	_, err := generation.tdlibClient.SetFileGenerationProgress(&client.SetFileGenerationProgressRequest{
+		GenerationID:    generation.ID,
+		ExpectedSize:    expectedSize,
+		LocalPrefixSize: localPrefixSize,
+	})
+
+	return err
+}
+
+// Create creates the destination file and returns writer which reports generation progress while writing.
+// Expected size of the generated file is 0 if unknown.
+func (generation *Generation) Create(expectedSize int32) (*Writer, error) {
+	file, err := os.Create(generation.DestinationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		generation:   generation,
+		file:         file,
+		expectedSize: expectedSize,
+	}, nil
+}
+
+// Writer writes generated file and reports generation progress to TDLib.
+type Writer struct {
+	generation   *Generation
+	mu           sync.Mutex
+	file         *os.File
+	expectedSize int32
+	written      int32
+	reported     int32
+	reportedAt   time.Time
+}
+
+// Write writes data to the destination file and reports the generated prefix size.
+// Progress is reported not more often than every progressInterval unless progressBytes are written since the last report.
+func (writer *Writer) Write(data []byte) (int, error) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	n, err := writer.file.Write(data)
+	writer.written += int32(n)
+	if err != nil {
+		return n, err
+	}
+
+	if writer.written-writer.reported < progressBytes && time.Since(writer.reportedAt) < progressInterval {
+		return n, nil
+	}
+
+	return n, writer.report()
+}
+
+// report reports the written size to TDLib. Must be called under the lock.
+func (writer *Writer) report() error {
+	err := writer.generation.SetProgress(writer.expectedSize, writer.written)
+	if err != nil {
+		return err
+	}
+
+	writer.reported = writer.written
+	writer.reportedAt = time.Now()
+
+	return nil
+}
+
+// Written returns the number of bytes written to the destination file.
+func (writer *Writer) Written() int32 {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	return writer.written
+}
+
+// Close reports progress which is not reported yet and closes the destination file.
+func (writer *Writer) Close() error {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	var err error
+	if writer.written != writer.reported {
+		err = writer.report()
+	}
+
+	closeErr := writer.file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,278 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+var (
+	// ErrUnsupportedConversion is error reported to TDLib when there is no handler for the requested conversion.
+	ErrUnsupportedConversion = errors.New("unsupported conversion")
+	// ErrStopped is error reported to TDLib when a generation is stopped before it finishes.
+	ErrStopped = errors.New("generation stopped")
+	// ErrDuplicateConversion is error returned when a handler for the conversion is already registered.
+	ErrDuplicateConversion = errors.New("conversion handler is already registered")
+)
+
+// Error is an error of a file generation with the code reported to TDLib.
+type Error struct {
+	Code    int32
+	Message string
+}
+
+// Error returns string describing the generation error.
+func (err *Error) Error() string {
+	return fmt.Sprintf("%d %s", err.Code, err.Message)
+}
+
+// Handler is a function type which generates the file. The context is cancelled when TDLib stops the generation.
+// Returned *Error is reported to TDLib with its code, other errors are reported with code 500.
+type Handler func(ctx context.Context, generation *Generation) error
+
+// Manager runs registered handlers of file generations requested by TDLib for inputFileGenerated.
+// Every started generation is finished with success or an error.
+// Manager must be running (see Run) or receive updates by HandleUpdate to generate files.
+type Manager struct {
+	tdlibClient *client.Client
+	mu          sync.Mutex
+	handlers    map[string]Handler
+	fallback    Handler
+	cancels     map[client.Int64JSON]context.CancelFunc
+}
+
+// Option is a function type which adjusts manager's configuration.
+type Option func(*Manager)
+
+// WithFallback configures the manager to use the handler for conversions without registered handlers.
+func WithFallback(handler Handler) Option {
+	return func(manager *Manager) {
+		manager.fallback = handler
+	}
+}
+
+// New creates new file generation manager.
+func New(tdlibClient *client.Client, options ...Option) *Manager {
+	manager := &Manager{
+		tdlibClient: tdlibClient,
+		handlers:    map[string]Handler{},
+		cancels:     map[client.Int64JSON]context.CancelFunc{},
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager
+}
+
+// Register registers the handler of the conversion.
+func (manager *Manager) Register(conversion string, handler Handler) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	if _, ok := manager.handlers[conversion]; ok {
+		return ErrDuplicateConversion
+	}
+
+	manager.handlers[conversion] = handler
+
+	return nil
+}
+
+// Handle registers the handler of the conversion and panics if the conversion is already registered.
+func (manager *Manager) Handle(conversion string, handler Handler) {
+	err := manager.Register(conversion, handler)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s", err, conversion))
+	}
+}
+
+// Run listens client's updates and generates files until the context is done.
+// Running generations are stopped when the context is done.
+func (manager *Manager) Run(ctx context.Context) error {
+	listener := manager.tdlibClient.GetListener()
+	defer listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			manager.stopAll()
+
+			return ctx.Err()
+
+		case update, ok := <-listener.Updates:
+			if !ok {
+				manager.stopAll()
+
+				return nil
+			}
+
+			if u, ok := update.(client.Update); ok {
+				manager.HandleUpdate(u)
+			}
+		}
+	}
+}
+
+// HandleUpdate starts generations by updateFileGenerationStart and stops them by updateFileGenerationStop.
+func (manager *Manager) HandleUpdate(update client.Update) {
+	switch u := update.(type) {
+	case *client.UpdateFileGenerationStart:
+		manager.start(&Generation{
+			tdlibClient:     manager.tdlibClient,
+			ID:              u.GenerationID,
+			OriginalPath:    u.OriginalPath,
+			DestinationPath: u.DestinationPath,
+			Conversion:      u.Conversion,
+		})
+
+	case *client.UpdateFileGenerationStop:
+		manager.stop(u.GenerationID)
+	}
+}
+
+func (manager *Manager) start(generation *Generation) {
+	manager.mu.Lock()
+	handler, ok := manager.handlers[generation.Conversion]
+	if !ok {
+		handler = manager.fallback
+	}
+
+	if handler == nil {
+		manager.mu.Unlock()
+		manager.finish(generation, ErrUnsupportedConversion)
+
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager.cancels[generation.ID] = cancel
+	manager.mu.Unlock()
+
+	go func() {
+		err := run(ctx, handler, generation)
+		if err == nil && ctx.Err() != nil {
+			err = ErrStopped
+		}
+
+		manager.mu.Lock()
+		delete(manager.cancels, generation.ID)
+		manager.mu.Unlock()
+		cancel()
+
+		manager.finish(generation, err)
+	}()
+}
+
+func run(ctx context.Context, handler Handler, generation *Generation) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = fmt.Errorf("panic: %v\n%s", value, debug.Stack())
+		}
+	}()
+
+	return handler(ctx, generation)
+}
+
+func (manager *Manager) stop(generationID client.Int64JSON) {
+	manager.mu.Lock()
+	cancel, ok := manager.cancels[generationID]
+	manager.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+func (manager *Manager) stopAll() {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	for _, cancel := range manager.cancels {
+		cancel()
+	}
+}
+
+func (manager *Manager) finish(generation *Generation, err error) {
+	var generationErr *client.Error
+	if err != nil {
+		generationErr = &client.Error{
+			Code:    500,
+			Message: err.Error(),
+		}
+
+		switch e := err.(type) {
+		case *Error:
+			generationErr.Code = e.Code
+			generationErr.Message = e.Message
+
+		default:
+			if err == ErrUnsupportedConversion {
+				generationErr.Code = 400
+			}
+		}
+	}
+
+	_, finishErr := manager.tdlibClient.FinishFileGeneration(&client.FinishFileGenerationRequest{
+		GenerationID: generation.ID,
+		Error:        generationErr,
+	})
+	if finishErr != nil {
+		log.Printf("finish file generation %d error: %s\n", generation.ID, finishErr)
+	}
+}
+
+// URL returns handler which downloads the file from the HTTP/HTTPS URL in OriginalPath.
+// The handler is intended for URLConversion. Default HTTP client is used if httpClient is nil.
+func URL(httpClient *http.Client) Handler {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return func(ctx context.Context, generation *Generation) error {
+		request, err := http.NewRequest(http.MethodGet, generation.OriginalPath, nil)
+		if err != nil {
+			return err
+		}
+
+		response, err := httpClient.Do(request.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return &Error{
+				Code:    int32(response.StatusCode),
+				Message: response.Status,
+			}
+		}
+
+		expectedSize := int32(0)
+		if response.ContentLength > 0 {
+			expectedSize = int32(response.ContentLength)
+		}
+
+		writer, err := generation.Create(expectedSize)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(writer, response.Body)
+		if err != nil {
+			writer.Close()
+
+			return err
+		}
+
+		return writer.Close()
+	}
+}
@@ -0,0 +1,232 @@
+package inline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/router"
+)
+
+// MaxResults is maximum number of results allowed by Telegram in a single answer to an inline query.
+const MaxResults = 50
+
+// MaxResultIDSize is maximum size of an inline query result identifier in bytes.
+const MaxResultIDSize = 64
+
+// ErrInvalidResultID is error returned when result identifier is empty, too long or duplicated.
+var ErrInvalidResultID = errors.New("invalid inline query result identifier")
+
+// Query contains inline query and parameters of the answer which can be adjusted by a handler.
+type Query struct {
+	// Unique query identifier
+	ID client.Int64JSON
+	// Identifier of the user who sent the query
+	SenderUserID int32
+	// User location, provided by the client; may be null
+	UserLocation *client.Location
+	// Text of the query
+	Query string
+	// Index of the first result to return
+	Offset int
+	// Allowed time to cache the results of the query, in seconds
+	CacheTime int32
+	// True, if the results of the query can be cached only for the user who sent the query
+	IsPersonal bool
+	// If non-empty, text of the button that opens a private chat with the bot
+	SwitchPmText string
+	// The parameter for the bot start message sent by the switch button
+	SwitchPmParameter string
+}
+
+// ChosenResult contains information about an inline query result chosen by a user.
+type ChosenResult struct {
+	// Identifier of the user who chose the result
+	SenderUserID int32
+	// User location, provided by the client; may be null
+	UserLocation *client.Location
+	// Text of the query
+	Query string
+	// Identifier of the chosen result
+	ResultID string
+	// Identifier of the sent inline message, if known
+	InlineMessageID string
+}
+
+// Handler is a function type which returns results of an inline query.
+// The whole result set should be returned regardless of the query offset, paging is handled automatically.
+type Handler func(ctx context.Context, query *Query) (Iterator, error)
+
+// ChosenHandler is a function type which handles a chosen inline query result.
+type ChosenHandler func(ctx context.Context, result *ChosenResult) error
+
+// Answerer answers inline queries with results returned by a handler page by page.
+type Answerer struct {
+	tdlibClient   *client.Client
+	handler       Handler
+	chosenHandler ChosenHandler
+	pageSize      int
+	cacheTime     int32
+	isPersonal    bool
+}
+
+// Option is a function type which adjusts answerer's configuration.
+type Option func(*Answerer)
+
+// WithPageSize configures the answerer to return specified number of results per page; at most MaxResults.
+func WithPageSize(pageSize int) Option {
+	return func(answerer *Answerer) {
+		if pageSize > 0 && pageSize <= MaxResults {
+			answerer.pageSize = pageSize
+		}
+	}
+}
+
+// WithCacheTime configures the answerer to use specified default cache time in seconds.
+func WithCacheTime(cacheTime int32) Option {
+	return func(answerer *Answerer) {
+		answerer.cacheTime = cacheTime
+	}
+}
+
+// WithPersonalResults configures the answerer to mark results as personal by default.
+func WithPersonalResults() Option {
+	return func(answerer *Answerer) {
+		answerer.isPersonal = true
+	}
+}
+
+// WithChosenHandler configures the answerer to pass chosen results to specified handler.
+func WithChosenHandler(chosenHandler ChosenHandler) Option {
+	return func(answerer *Answerer) {
+		answerer.chosenHandler = chosenHandler
+	}
+}
+
+// New creates new inline query answerer.
+func New(tdlibClient *client.Client, handler Handler, options ...Option) *Answerer {
+	answerer := &Answerer{
+		tdlibClient: tdlibClient,
+		handler:     handler,
+		pageSize:    MaxResults,
+		cacheTime:   300,
+	}
+
+	for _, option := range options {
+		option(answerer)
+	}
+
+	return answerer
+}
+
+// HandleQuery builds the page of results for the query and answers it.
+func (answerer *Answerer) HandleQuery(ctx context.Context, update *client.UpdateNewInlineQuery) error {
+	// offset is an opaque string for Telegram, the answerer always uses an index of the first result
+	offset, _ := strconv.Atoi(update.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := &Query{
+		ID:           update.ID,
+		SenderUserID: update.SenderUserID,
+		UserLocation: update.UserLocation,
+		Query:        update.Query,
+		Offset:       offset,
+		CacheTime:    answerer.cacheTime,
+		IsPersonal:   answerer.isPersonal,
+	}
+
+	iterator, err := answerer.handler(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	results, hasMore, err := answerer.page(iterator, offset)
+	if err != nil {
+		return err
+	}
+
+	var nextOffset string
+	if hasMore {
+		nextOffset = strconv.Itoa(offset + len(results))
+	}
+
+	_, err = answerer.tdlibClient.AnswerInlineQuery(&client.AnswerInlineQueryRequest{
+		InlineQueryID:     query.ID,
+		IsPersonal:        query.IsPersonal,
+		Results:           results,
+		CacheTime:         query.CacheTime,
+		NextOffset:        nextOffset,
+		SwitchPmText:      query.SwitchPmText,
+		SwitchPmParameter: query.SwitchPmParameter,
+	})
+
+	return err
+}
+
+func (answerer *Answerer) page(iterator Iterator, offset int) ([]client.InputInlineQueryResult, bool, error) {
+	results := []client.InputInlineQueryResult{}
+	if iterator == nil {
+		return results, false, nil
+	}
+
+	if skipper, ok := iterator.(Skipper); ok {
+		skipper.Skip(offset)
+	} else {
+		for i := 0; i < offset; i++ {
+			_, ok := iterator.Next()
+			if !ok {
+				return results, false, nil
+			}
+		}
+	}
+
+	ids := map[string]bool{}
+
+	for len(results) < answerer.pageSize {
+		result, ok := iterator.Next()
+		if !ok {
+			return results, false, nil
+		}
+
+		id := ResultID(result)
+		if id == "" || len(id) > MaxResultIDSize || ids[id] {
+			return nil, false, fmt.Errorf("%s: %q", ErrInvalidResultID, id)
+		}
+		ids[id] = true
+
+		results = append(results, result)
+	}
+
+	_, hasMore := iterator.Next()
+
+	return results, hasMore, nil
+}
+
+// HandleChosenResult passes the chosen result to the chosen handler if it is configured.
+func (answerer *Answerer) HandleChosenResult(ctx context.Context, update *client.UpdateNewChosenInlineResult) error {
+	if answerer.chosenHandler == nil {
+		return nil
+	}
+
+	return answerer.chosenHandler(ctx, &ChosenResult{
+		SenderUserID:    update.SenderUserID,
+		UserLocation:    update.UserLocation,
+		Query:           update.Query,
+		ResultID:        update.ResultID,
+		InlineMessageID: update.InlineMessageID,
+	})
+}
+
+// Mount registers the answerer as a handler of inline queries and chosen inline results in the update router.
+func (answerer *Answerer) Mount(updateRouter *router.Router) {
+	updateRouter.Handle(client.TypeUpdateNewInlineQuery, func(ctx context.Context, update client.Update) error {
+		return answerer.HandleQuery(ctx, update.(*client.UpdateNewInlineQuery))
+	})
+	updateRouter.Handle(client.TypeUpdateNewChosenInlineResult, func(ctx context.Context, update client.Update) error {
+		return answerer.HandleChosenResult(ctx, update.(*client.UpdateNewChosenInlineResult))
+	})
+}
@@ -0,0 +1,101 @@
+package inline
+
+import (
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Iterator iterates over results of an inline query.
+type Iterator interface {
+	// Next returns next result; false if there are no more results
+	Next() (client.InputInlineQueryResult, bool)
+}
+
+// Skipper is an optional interface of Iterator which allows to skip results efficiently.
+type Skipper interface {
+	// Skip skips n results
+	Skip(n int)
+}
+
+// Slice returns iterator over the results.
+func Slice(results ...client.InputInlineQueryResult) Iterator {
+	return &sliceIterator{
+		results: results,
+	}
+}
+
+type sliceIterator struct {
+	results []client.InputInlineQueryResult
+	index   int
+}
+
+func (iterator *sliceIterator) Next() (client.InputInlineQueryResult, bool) {
+	if iterator.index >= len(iterator.results) {
+		return nil, false
+	}
+
+	result := iterator.results[iterator.index]
+	iterator.index++
+
+	return result, true
+}
+
+func (iterator *sliceIterator) Skip(n int) {
+	iterator.index += n
+}
+
+// Func returns iterator calling the function with sequential indexes starting from zero until it returns false.
+func Func(fn func(index int) (client.InputInlineQueryResult, bool)) Iterator {
+	return &funcIterator{
+		fn: fn,
+	}
+}
+
+type funcIterator struct {
+	fn    func(index int) (client.InputInlineQueryResult, bool)
+	index int
+}
+
+func (iterator *funcIterator) Next() (client.InputInlineQueryResult, bool) {
+	result, ok := iterator.fn(iterator.index)
+	if ok {
+		iterator.index++
+	}
+
+	return result, ok
+}
+
+func (iterator *funcIterator) Skip(n int) {
+	iterator.index += n
+}
+
+// ResultID returns identifier of the inline query result.
+func ResultID(result client.InputInlineQueryResult) string {
+	switch r := result.(type) {
+	case *client.InputInlineQueryResultAnimatedGif:
+		return r.ID
+	case *client.InputInlineQueryResultArticle:
+		return r.ID
+	case *client.InputInlineQueryResultAudio:
+		return r.ID
+	case *client.InputInlineQueryResultContact:
+		return r.ID
+	case *client.InputInlineQueryResultDocument:
+		return r.ID
+	case *client.InputInlineQueryResultGame:
+		return r.ID
+	case *client.InputInlineQueryResultLocation:
+		return r.ID
+	case *client.InputInlineQueryResultPhoto:
+		return r.ID
+	case *client.InputInlineQueryResultSticker:
+		return r.ID
+	case *client.InputInlineQueryResultVenue:
+		return r.ID
+	case *client.InputInlineQueryResultVideo:
+		return r.ID
+	case *client.InputInlineQueryResultVoiceNote:
+		return r.ID
+	}
+
+	return ""
+}
@@ -0,0 +1,222 @@
+package keyboard
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/callback"
+)
+
+const (
+	// MaxRowButtons is maximum number of buttons in a keyboard row.
+	MaxRowButtons = 8
+	// MaxButtons is maximum number of buttons in a keyboard.
+	MaxButtons = 100
+)
+
+var (
+	// ErrEmptyKeyboard is error returned when a keyboard has no buttons.
+	ErrEmptyKeyboard = errors.New("keyboard has no buttons")
+	// ErrTooManyButtons is error returned when a keyboard or its row has too many buttons.
+	ErrTooManyButtons = errors.New("too many buttons")
+	// ErrInvalidButton is error returned when a button is malformed.
+	ErrInvalidButton = errors.New("invalid button")
+)
+
+// InlineBuilder builds inline keyboards shown below messages.
+type InlineBuilder struct {
+	rows [][]*client.InlineKeyboardButton
+}
+
+// NewInline creates new inline keyboard builder.
+func NewInline() *InlineBuilder {
+	return &InlineBuilder{}
+}
+
+// Row appends a row of buttons.
+func (builder *InlineBuilder) Row(buttons ...*client.InlineKeyboardButton) *InlineBuilder {
+	builder.rows = append(builder.rows, buttons)
+
+	return builder
+}
+
+// Grid appends buttons split into rows of specified width.
+func (builder *InlineBuilder) Grid(width int, buttons ...*client.InlineKeyboardButton) *InlineBuilder {
+	if width <= 0 {
+		width = MaxRowButtons
+	}
+
+	for len(buttons) > 0 {
+		n := width
+		if n > len(buttons) {
+			n = len(buttons)
+		}
+		builder.Row(buttons[:n]...)
+		buttons = buttons[n:]
+	}
+
+	return builder
+}
+
+// Build validates the keyboard and returns it as a reply markup.
+func (builder *InlineBuilder) Build() (*client.ReplyMarkupInlineKeyboard, error) {
+	var total int
+	for i, row := range builder.rows {
+		err := validateRow(i, len(row))
+		if err != nil {
+			return nil, err
+		}
+		total += len(row)
+
+		for j, button := range row {
+			err := validateInlineButton(button, i == 0 && j == 0)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, button %d: %s", i, j, err)
+			}
+		}
+	}
+
+	err := validateTotal(total)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.ReplyMarkupInlineKeyboard{
+		Rows: builder.rows,
+	}, nil
+}
+
+func validateInlineButton(button *client.InlineKeyboardButton, isFirst bool) error {
+	if button == nil || button.Text == "" || button.Type == nil {
+		return fmt.Errorf("%s: text and type are required", ErrInvalidButton)
+	}
+
+	switch t := button.Type.(type) {
+	case *client.InlineKeyboardButtonTypeURL:
+		if t.URL == "" {
+			return fmt.Errorf("%s: URL is required", ErrInvalidButton)
+		}
+
+	case *client.InlineKeyboardButtonTypeCallback:
+		if len(t.Data) == 0 {
+			return fmt.Errorf("%s: callback data is required", ErrInvalidButton)
+		}
+
+		return callback.ValidateData(t.Data)
+
+	case *client.InlineKeyboardButtonTypeCallbackGame, *client.InlineKeyboardButtonTypeBuy:
+		if !isFirst {
+			return fmt.Errorf("%s: %s button must be the first button of the first row", ErrInvalidButton, t.InlineKeyboardButtonTypeType())
+		}
+	}
+
+	return nil
+}
+
+// ReplyBuilder builds custom reply keyboards shown instead of the user's keyboard.
+type ReplyBuilder struct {
+	keyboard *client.ReplyMarkupShowKeyboard
+}
+
+// NewReply creates new reply keyboard builder.
+func NewReply() *ReplyBuilder {
+	return &ReplyBuilder{
+		keyboard: &client.ReplyMarkupShowKeyboard{},
+	}
+}
+
+// Row appends a row of buttons.
+func (builder *ReplyBuilder) Row(buttons ...*client.KeyboardButton) *ReplyBuilder {
+	builder.keyboard.Rows = append(builder.keyboard.Rows, buttons)
+
+	return builder
+}
+
+// Grid appends buttons split into rows of specified width.
+func (builder *ReplyBuilder) Grid(width int, buttons ...*client.KeyboardButton) *ReplyBuilder {
+	if width <= 0 {
+		width = MaxRowButtons
+	}
+
+	for len(buttons) > 0 {
+		n := width
+		if n > len(buttons) {
+			n = len(buttons)
+		}
+		builder.Row(buttons[:n]...)
+		buttons = buttons[n:]
+	}
+
+	return builder
+}
+
+// Resize requests clients to resize the keyboard vertically.
+func (builder *ReplyBuilder) Resize() *ReplyBuilder {
+	builder.keyboard.ResizeKeyboard = true
+
+	return builder
+}
+
+// OneTime requests clients to hide the keyboard after use.
+func (builder *ReplyBuilder) OneTime() *ReplyBuilder {
+	builder.keyboard.OneTime = true
+
+	return builder
+}
+
+// Personal shows the keyboard only to the mentioned users and to the target user of a reply.
+func (builder *ReplyBuilder) Personal() *ReplyBuilder {
+	builder.keyboard.IsPersonal = true
+
+	return builder
+}
+
+// Build validates the keyboard and returns it as a reply markup.
+func (builder *ReplyBuilder) Build() (*client.ReplyMarkupShowKeyboard, error) {
+	var total int
+	for i, row := range builder.keyboard.Rows {
+		err := validateRow(i, len(row))
+		if err != nil {
+			return nil, err
+		}
+		total += len(row)
+
+		for j, button := range row {
+			if button == nil || button.Text == "" || button.Type == nil {
+				return nil, fmt.Errorf("row %d, button %d: %s: text and type are required", i, j, ErrInvalidButton)
+			}
+		}
+	}
+
+	err := validateTotal(total)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.keyboard, nil
+}
+
+func validateRow(index int, size int) error {
+	if size == 0 {
+		return fmt.Errorf("row %d: %s", index, ErrEmptyKeyboard)
+	}
+
+	if size > MaxRowButtons {
+		return fmt.Errorf("row %d: %s: %d, max %d", index, ErrTooManyButtons, size, MaxRowButtons)
+	}
+
+	return nil
+}
+
+func validateTotal(total int) error {
+	if total == 0 {
+		return ErrEmptyKeyboard
+	}
+
+	if total > MaxButtons {
+		return fmt.Errorf("%s: %d, max %d", ErrTooManyButtons, total, MaxButtons)
+	}
+
+	return nil
+}
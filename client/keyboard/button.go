@@ -0,0 +1,109 @@
+package keyboard
+
+import (
+	"github.com/u-robot/go-tdlib/client"
+	"github.com/u-robot/go-tdlib/client/callback"
+)
+
+// URL creates inline keyboard button opening the HTTP or tg:// URL.
+func URL(text string, url string) *client.InlineKeyboardButton {
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeURL{
+			URL: url,
+		},
+	}
+}
+
+// Callback creates inline keyboard button sending the data to the bot via a callback query.
+func Callback(text string, data []byte) *client.InlineKeyboardButton {
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeCallback{
+			Data: data,
+		},
+	}
+}
+
+// CallbackData creates inline keyboard button sending structured data built by callback.Data.
+// Data size is checked when the keyboard is built.
+func CallbackData(text string, prefix string, args ...string) *client.InlineKeyboardButton {
+	data, _ := callback.Data(prefix, args...)
+
+	return Callback(text, data)
+}
+
+// CallbackGame creates inline keyboard button starting a game.
+func CallbackGame(text string) *client.InlineKeyboardButton {
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeCallbackGame{},
+	}
+}
+
+// SwitchInline creates inline keyboard button asking the user to choose a chat and sending the inline query to the bot there.
+func SwitchInline(text string, query string) *client.InlineKeyboardButton {
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeSwitchInline{
+			Query: query,
+		},
+	}
+}
+
+// SwitchInlineCurrentChat creates inline keyboard button sending the inline query to the bot in the current chat.
+func SwitchInlineCurrentChat(text string, query string) *client.InlineKeyboardButton {
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeSwitchInline{
+			Query:         query,
+			InCurrentChat: true,
+		},
+	}
+}
+
+// Buy creates inline keyboard button with a buy option.
+func Buy(text string) *client.InlineKeyboardButton {
+	return &client.InlineKeyboardButton{
+		Text: text,
+		Type: &client.InlineKeyboardButtonTypeBuy{},
+	}
+}
+
+// Text creates reply keyboard button sending its text.
+func Text(text string) *client.KeyboardButton {
+	return &client.KeyboardButton{
+		Text: text,
+		Type: &client.KeyboardButtonTypeText{},
+	}
+}
+
+// RequestPhoneNumber creates reply keyboard button sending the user's phone number.
+func RequestPhoneNumber(text string) *client.KeyboardButton {
+	return &client.KeyboardButton{
+		Text: text,
+		Type: &client.KeyboardButtonTypeRequestPhoneNumber{},
+	}
+}
+
+// RequestLocation creates reply keyboard button sending the user's location.
+func RequestLocation(text string) *client.KeyboardButton {
+	return &client.KeyboardButton{
+		Text: text,
+		Type: &client.KeyboardButtonTypeRequestLocation{},
+	}
+}
+
+// Remove creates reply markup removing the reply keyboard.
+func Remove(isPersonal bool) *client.ReplyMarkupRemoveKeyboard {
+	return &client.ReplyMarkupRemoveKeyboard{
+		IsPersonal: isPersonal,
+	}
+}
+
+// ForceReply creates reply markup forcing the user to reply to the message.
+func ForceReply(isPersonal bool) *client.ReplyMarkupForceReply {
+	return &client.ReplyMarkupForceReply{
+		IsPersonal: isPersonal,
+	}
+}
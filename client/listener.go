@@ -46,18 +46,30 @@ func (store *listenerStore) gc() {
 
 // Listener implements simple object handling update events.
 type Listener struct {
-	mu       sync.Mutex
-	isActive bool
-	Updates  chan Type
+	mu        sync.Mutex
+	isActive  bool
+	Updates   chan Type
+	done      chan struct{}
+	closeOnce sync.Once
+	// sendMu is held while sending to the updates channel, so the channel is not closed during sending
+	sendMu sync.Mutex
 }
 
-// Close safely closes listener.
+// Close safely closes listener. The updates channel is closed only after the client stops sending to it.
 func (listener *Listener) Close() {
-	listener.mu.Lock()
-	defer listener.mu.Unlock()
+	listener.closeOnce.Do(func() {
+		// interrupt sending blocked on the full channel
+		close(listener.done)
+
+		listener.sendMu.Lock()
+		defer listener.sendMu.Unlock()
+
+		listener.mu.Lock()
+		listener.isActive = false
+		listener.mu.Unlock()
 
-	listener.isActive = false
-	close(listener.Updates)
+		close(listener.Updates)
+	})
 }
 
 // IsActive returns true if listener is active and is listening update events.
@@ -67,3 +79,29 @@ func (listener *Listener) IsActive() bool {
 
 	return listener.isActive
 }
+
+// send sends the update to the listener. It returns false if the listener is closed.
+// The listener's lock is not held while waiting for the consumer.
+func (listener *Listener) send(typ Type) bool {
+	if !listener.IsActive() {
+		return false
+	}
+
+	listener.sendMu.Lock()
+	defer listener.sendMu.Unlock()
+
+	// the updates channel is closed only after done
+	select {
+	case <-listener.done:
+		return false
+	default:
+	}
+
+	select {
+	case listener.Updates <- typ:
+		return true
+
+	case <-listener.done:
+		return false
+	}
+}
@@ -0,0 +1,309 @@
+package markup
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+type htmlElement struct {
+	tag      string
+	position int
+	offset   int32
+	href     string
+	language string
+	// true for <code> merged into enclosing <pre> as its language
+	merged bool
+}
+
+// ParseHTML converts the safe subset of HTML into formatted text.
+// Supported tags are <b>, <strong>, <i>, <em>, <code>, <pre>, <pre><code class="language-go">
+// and <a href="...">; links to tg://user?id=123 become mentions of users. Tags can be nested,
+// except inside <code> and <pre>. Named entities &lt; &gt; &amp; &quot; and numeric entities are supported.
+func ParseHTML(text string) (*client.FormattedText, error) {
+	source := []rune(text)
+	out := &output{}
+
+	var stack []*htmlElement
+
+	for i := 0; i < len(source); {
+		switch source[i] {
+		case '&':
+			r, next, ok := parseHTMLEntity(source, i)
+			if !ok {
+				return nil, newParseError(source, i, "invalid character entity, use &amp; for a literal ampersand")
+			}
+			out.writeRune(r)
+			i = next
+
+		case '<':
+			if isHTMLEndTag(source, i) {
+				end := indexString(source, i+1, ">")
+				if end < 0 {
+					return nil, newParseError(source, i, "unclosed tag, use &lt; for a literal less-than sign")
+				}
+
+				tag := strings.TrimSpace(string(source[i+1 : end]))
+				name := strings.ToLower(strings.TrimSpace(tag[1:]))
+				if len(stack) == 0 {
+					return nil, newParseError(source, i, "unexpected end tag </%s>", name)
+				}
+
+				element := stack[len(stack)-1]
+				if element.tag != name {
+					line, column := locate(source, element.position)
+
+					return nil, newParseError(source, i, "end tag </%s> doesn't match start tag <%s> at line %d, column %d", name, element.tag, line, column)
+				}
+				stack = stack[:len(stack)-1]
+
+				err := closeHTMLElement(out, element)
+				if err != nil {
+					return nil, newParseError(source, element.position, "%s", err)
+				}
+
+				i = end + 1
+
+				continue
+			}
+
+			name, attributes, next, ok := parseHTMLTag(source, i)
+			if next < 0 {
+				return nil, newParseError(source, i, "unclosed tag, use &lt; for a literal less-than sign")
+			}
+			if !ok {
+				return nil, newParseError(source, i, "malformed tag <%s>", strings.TrimSpace(string(source[i+1:next-1])))
+			}
+
+			element := &htmlElement{
+				tag:      name,
+				position: i,
+				offset:   out.length,
+			}
+
+			switch name {
+			case "b", "strong", "i", "em", "code", "pre":
+			case "a":
+				element.href = attributes["href"]
+			default:
+				return nil, newParseError(source, i, "unsupported tag <%s>", name)
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				switch {
+				case parent.tag == "pre" && name == "code" && parent.offset == out.length && parent.language == "":
+					element.merged = true
+					parent.language = strings.TrimPrefix(attributes["class"], "language-")
+				case parent.tag == "pre" || parent.tag == "code":
+					return nil, newParseError(source, i, "tag <%s> can't be nested into <%s>", name, parent.tag)
+				}
+			}
+
+			stack = append(stack, element)
+			i = next
+
+		default:
+			out.writeRune(source[i])
+			i++
+		}
+	}
+
+	if len(stack) > 0 {
+		element := stack[len(stack)-1]
+
+		return nil, newParseError(source, element.position, "unclosed tag <%s>", element.tag)
+	}
+
+	return out.formattedText(), nil
+}
+
+func closeHTMLElement(out *output, element *htmlElement) error {
+	switch element.tag {
+	case "b", "strong":
+		out.addEntity(element.offset, &client.TextEntityTypeBold{})
+	case "i", "em":
+		out.addEntity(element.offset, &client.TextEntityTypeItalic{})
+	case "code":
+		if !element.merged {
+			out.addEntity(element.offset, &client.TextEntityTypeCode{})
+		}
+	case "pre":
+		if element.language != "" {
+			out.addEntity(element.offset, &client.TextEntityTypePreCode{
+				Language: element.language,
+			})
+		} else {
+			out.addEntity(element.offset, &client.TextEntityTypePre{})
+		}
+	case "a":
+		entityType, err := linkEntityType(element.href)
+		if err != nil {
+			return err
+		}
+		out.addEntity(element.offset, entityType)
+	}
+
+	return nil
+}
+
+// isHTMLEndTag reports whether the tag at the position is an end tag.
+func isHTMLEndTag(source []rune, position int) bool {
+	i := skipSpaces(source, position+1)
+
+	return i < len(source) && source[i] == '/'
+}
+
+// parseHTMLTag parses name and attributes of the start tag at the position and returns position after the tag.
+// Quoted attribute values can contain '>'. The returned position is -1 if the tag is not closed.
+func parseHTMLTag(source []rune, position int) (string, map[string]string, int, bool) {
+	i := skipSpaces(source, position+1)
+
+	start := i
+	for i < len(source) && !unicode.IsSpace(source[i]) && source[i] != '>' && source[i] != '/' {
+		i++
+	}
+	name := strings.ToLower(string(source[start:i]))
+
+	attributes := map[string]string{}
+	ok := name != ""
+
+	for {
+		i = skipSpaces(source, i)
+		if i >= len(source) {
+			return "", nil, -1, false
+		}
+
+		switch source[i] {
+		case '>':
+			return name, attributes, i + 1, ok
+
+		case '/':
+			i++
+
+			continue
+		}
+
+		start = i
+		for i < len(source) && !unicode.IsSpace(source[i]) && source[i] != '=' && source[i] != '>' && source[i] != '/' {
+			i++
+		}
+		key := strings.ToLower(string(source[start:i]))
+
+		i = skipSpaces(source, i)
+		if i >= len(source) || source[i] != '=' {
+			attributes[key] = ""
+
+			continue
+		}
+		i = skipSpaces(source, i+1)
+		if i >= len(source) {
+			return "", nil, -1, false
+		}
+
+		var value string
+		if quote := source[i]; quote == '"' || quote == '\'' {
+			start = i + 1
+			i = start
+			for i < len(source) && source[i] != quote {
+				i++
+			}
+			if i >= len(source) {
+				return "", nil, -1, false
+			}
+			value = string(source[start:i])
+			i++
+		} else {
+			start = i
+			for i < len(source) && !unicode.IsSpace(source[i]) && source[i] != '>' {
+				i++
+			}
+			value = string(source[start:i])
+		}
+
+		unescaped, valid := unescapeHTML(value)
+		if !valid {
+			ok = false
+		}
+		attributes[key] = unescaped
+	}
+}
+
+// skipSpaces returns position of the first character after the position which is not a space.
+func skipSpaces(source []rune, position int) int {
+	for position < len(source) && unicode.IsSpace(source[position]) {
+		position++
+	}
+
+	return position
+}
+
+// parseHTMLEntity parses character entity at the position and returns the character and position after the entity.
+func parseHTMLEntity(source []rune, position int) (rune, int, bool) {
+	end := -1
+	for i := position + 1; i < len(source) && i < position+12; i++ {
+		if source[i] == ';' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0, 0, false
+	}
+
+	name := string(source[position+1 : end])
+	switch name {
+	case "lt":
+		return '<', end + 1, true
+	case "gt":
+		return '>', end + 1, true
+	case "amp":
+		return '&', end + 1, true
+	case "quot":
+		return '"', end + 1, true
+	case "apos":
+		return '\'', end + 1, true
+	}
+
+	if !strings.HasPrefix(name, "#") {
+		return 0, 0, false
+	}
+
+	var code int64
+	var err error
+	if strings.HasPrefix(name, "#x") || strings.HasPrefix(name, "#X") {
+		code, err = strconv.ParseInt(name[2:], 16, 32)
+	} else {
+		code, err = strconv.ParseInt(name[1:], 10, 32)
+	}
+	if err != nil || code <= 0 || code > unicode.MaxRune {
+		return 0, 0, false
+	}
+
+	return rune(code), end + 1, true
+}
+
+func unescapeHTML(value string) (string, bool) {
+	source := []rune(value)
+
+	var builder strings.Builder
+	for i := 0; i < len(source); {
+		if source[i] != '&' {
+			builder.WriteRune(source[i])
+			i++
+
+			continue
+		}
+
+		r, next, ok := parseHTMLEntity(source, i)
+		if !ok {
+			return "", false
+		}
+		builder.WriteRune(r)
+		i = next
+	}
+
+	return builder.String(), true
+}
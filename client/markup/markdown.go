@@ -0,0 +1,184 @@
+package markup
+
+import (
+	"strings"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// markdownSpecial contains characters which must be escaped with a backslash to be used literally.
+const markdownSpecial = "*_`[]()\\"
+
+// ParseMarkdown converts Markdown into formatted text.
+// Supported syntax is *bold*, _italic_, `code`, ```pre```, ```language
+// pre code```, [text URL](http://example.com) and [mention](tg://user?id=123).
+// Entities can not be nested. Special characters are escaped with a backslash.
+func ParseMarkdown(text string) (*client.FormattedText, error) {
+	source := []rune(text)
+	out := &output{}
+
+	for i := 0; i < len(source); {
+		switch r := source[i]; r {
+		case '\\':
+			if i+1 < len(source) && strings.ContainsRune(markdownSpecial, source[i+1]) {
+				out.writeRune(source[i+1])
+				i += 2
+			} else {
+				out.writeRune(r)
+				i++
+			}
+
+		case '*', '_':
+			end := findClosing(source, i+1, r)
+			if end < 0 {
+				return nil, newParseError(source, i, "can't find end of the entity starting with %q", r)
+			}
+
+			offset := out.length
+			out.writeString(unescapeMarkdown(source[i+1 : end]))
+			if r == '*' {
+				out.addEntity(offset, &client.TextEntityTypeBold{})
+			} else {
+				out.addEntity(offset, &client.TextEntityTypeItalic{})
+			}
+			i = end + 1
+
+		case '`':
+			if hasPrefix(source[i:], "```") {
+				end := indexString(source, i+3, "```")
+				if end < 0 {
+					return nil, newParseError(source, i, "can't find end of the pre-formatted block")
+				}
+
+				content := string(source[i+3 : end])
+				language := ""
+				if newline := strings.IndexByte(content, '\n'); newline >= 0 && isLanguage(content[:newline]) {
+					language = content[:newline]
+					content = content[newline+1:]
+				}
+
+				offset := out.length
+				out.writeString(content)
+				if language != "" {
+					out.addEntity(offset, &client.TextEntityTypePreCode{
+						Language: language,
+					})
+				} else {
+					out.addEntity(offset, &client.TextEntityTypePre{})
+				}
+				i = end + 3
+
+				continue
+			}
+
+			end := indexString(source, i+1, "`")
+			if end < 0 {
+				return nil, newParseError(source, i, "can't find end of the code entity")
+			}
+
+			offset := out.length
+			out.writeString(string(source[i+1 : end]))
+			out.addEntity(offset, &client.TextEntityTypeCode{})
+			i = end + 1
+
+		case '[':
+			textEnd := findClosing(source, i+1, ']')
+			if textEnd < 0 {
+				return nil, newParseError(source, i, "can't find end of the link text")
+			}
+
+			if textEnd+1 >= len(source) || source[textEnd+1] != '(' {
+				return nil, newParseError(source, textEnd+1, "expected ( with URL after the link text")
+			}
+
+			urlEnd := findClosing(source, textEnd+2, ')')
+			if urlEnd < 0 {
+				return nil, newParseError(source, textEnd+1, "can't find end of the link URL")
+			}
+
+			entityType, err := linkEntityType(strings.TrimSpace(unescapeMarkdown(source[textEnd+2 : urlEnd])))
+			if err != nil {
+				return nil, newParseError(source, textEnd+2, "%s", err)
+			}
+
+			offset := out.length
+			out.writeString(unescapeMarkdown(source[i+1 : textEnd]))
+			out.addEntity(offset, entityType)
+			i = urlEnd + 1
+
+		case ']', ')':
+			return nil, newParseError(source, i, "unexpected %q, escape it with a backslash", r)
+
+		default:
+			out.writeRune(r)
+			i++
+		}
+	}
+
+	return out.formattedText(), nil
+}
+
+// findClosing returns index of the first unescaped delimiter starting from the position or -1.
+func findClosing(source []rune, position int, delimiter rune) int {
+	for i := position; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case delimiter:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unescapeMarkdown(source []rune) string {
+	var builder strings.Builder
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\\' && i+1 < len(source) && strings.ContainsRune(markdownSpecial, source[i+1]) {
+			i++
+		}
+		builder.WriteRune(source[i])
+	}
+
+	return builder.String()
+}
+
+func hasPrefix(source []rune, prefix string) bool {
+	runes := []rune(prefix)
+	if len(source) < len(runes) {
+		return false
+	}
+
+	return string(source[:len(runes)]) == prefix
+}
+
+func indexString(source []rune, position int, substring string) int {
+	if position > len(source) {
+		return -1
+	}
+
+	index := strings.Index(string(source[position:]), substring)
+	if index < 0 {
+		return -1
+	}
+
+	return position + len([]rune(string(source[position:])[:index]))
+}
+
+// isLanguage reports whether the first line of a pre-formatted block looks like a name of a programming language.
+func isLanguage(line string) bool {
+	if line == "" {
+		return false
+	}
+
+	for _, r := range line {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("+#-_.", r):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,139 @@
+package markup
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// mentionURLPrefix is a prefix of links which are converted to mentions of users by their identifiers.
+const mentionURLPrefix = "tg://user?id="
+
+// ParseError describes invalid markup and its position in the source text.
+type ParseError struct {
+	// Offset of the error in bytes
+	Offset int
+	// Line of the error, starting from 1
+	Line int
+	// Column of the error in characters, starting from 1
+	Column int
+	// Description of the error
+	Message string
+}
+
+// Error returns string describing the error and its position.
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", err.Line, err.Column, err.Message)
+}
+
+func newParseError(source []rune, position int, format string, args ...interface{}) *ParseError {
+	line, column := locate(source, position)
+
+	return &ParseError{
+		Offset:  len(string(source[:position])),
+		Line:    line,
+		Column:  column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// locate returns line and column of the rune at the position.
+func locate(source []rune, position int) (int, int) {
+	line, column := 1, 1
+	for _, r := range source[:position] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	return line, column
+}
+
+// output accumulates text and entities measuring offsets in UTF-16 code units.
+type output struct {
+	text     strings.Builder
+	length   int32
+	entities []*client.TextEntity
+}
+
+func (out *output) writeRune(r rune) {
+	out.text.WriteRune(r)
+	out.length += utf16Len(r)
+}
+
+func (out *output) writeString(s string) {
+	for _, r := range s {
+		out.writeRune(r)
+	}
+}
+
+func (out *output) addEntity(offset int32, entityType client.TextEntityType) {
+	if out.length <= offset {
+		return
+	}
+
+	out.entities = append(out.entities, &client.TextEntity{
+		Offset: offset,
+		Length: out.length - offset,
+		Type:   entityType,
+	})
+}
+
+func (out *output) formattedText() *client.FormattedText {
+	entities := out.entities
+	if entities == nil {
+		entities = []*client.TextEntity{}
+	}
+
+	// outer entities go first
+	sort.SliceStable(entities, func(i, j int) bool {
+		if entities[i].Offset != entities[j].Offset {
+			return entities[i].Offset < entities[j].Offset
+		}
+
+		return entities[i].Length > entities[j].Length
+	})
+
+	return &client.FormattedText{
+		Text:     out.text.String(),
+		Entities: entities,
+	}
+}
+
+// utf16Len returns number of UTF-16 code units encoding the rune.
+func utf16Len(r rune) int32 {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+
+	return 1
+}
+
+// linkEntityType returns entity type of a link, links to tg://user?id= are mentions of users.
+func linkEntityType(url string) (client.TextEntityType, error) {
+	if strings.HasPrefix(url, mentionURLPrefix) {
+		userID, err := strconv.ParseInt(strings.TrimPrefix(url, mentionURLPrefix), 10, 32)
+		if err != nil || userID <= 0 {
+			return nil, fmt.Errorf("invalid user identifier in %q", url)
+		}
+
+		return &client.TextEntityTypeMentionName{
+			UserID: int32(userID),
+		}, nil
+	}
+
+	if url == "" {
+		return nil, fmt.Errorf("empty URL")
+	}
+
+	return &client.TextEntityTypeTextURL{
+		URL: url,
+	}, nil
+}
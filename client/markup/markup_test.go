@@ -0,0 +1,356 @@
+package markup
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// describe returns entities as "type offset+length" strings, e.g. "textEntityTypeBold 0+4".
+func describe(entities []*client.TextEntity) []string {
+	descriptions := []string{}
+	for _, entity := range entities {
+		description := fmt.Sprintf("%s %d+%d", entity.Type.TextEntityTypeType(), entity.Offset, entity.Length)
+		switch t := entity.Type.(type) {
+		case *client.TextEntityTypeTextURL:
+			description += " " + t.URL
+		case *client.TextEntityTypeMentionName:
+			description += fmt.Sprintf(" %d", t.UserID)
+		case *client.TextEntityTypePreCode:
+			description += " " + t.Language
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	return descriptions
+}
+
+func bold(offset int32, length int32) *client.TextEntity {
+	return &client.TextEntity{Offset: offset, Length: length, Type: &client.TextEntityTypeBold{}}
+}
+
+func italic(offset int32, length int32) *client.TextEntity {
+	return &client.TextEntity{Offset: offset, Length: length, Type: &client.TextEntityTypeItalic{}}
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		text     string
+		entities []string
+	}{
+		{
+			name:     "surrogate pair before entity",
+			html:     "😀 <b>bold</b>",
+			text:     "😀 bold",
+			entities: []string{"textEntityTypeBold 3+4"},
+		},
+		{
+			name:     "surrogate pair inside entity",
+			html:     "<i>a😀b</i>c",
+			text:     "a😀bc",
+			entities: []string{"textEntityTypeItalic 0+4"},
+		},
+		{
+			name:     "nested entities",
+			html:     "<b>a<i>b</i>c</b>",
+			text:     "abc",
+			entities: []string{"textEntityTypeBold 0+3", "textEntityTypeItalic 1+1"},
+		},
+		{
+			name:     "pre with language",
+			html:     `<pre><code class="language-go">x := 1</code></pre>`,
+			text:     "x := 1",
+			entities: []string{"textEntityTypePreCode 0+6 go"},
+		},
+		{
+			name:     "links and mentions",
+			html:     `<a href="http://example.com/?a=1&amp;b=2">link</a> <a href="tg://user?id=123">user</a>`,
+			text:     "link user",
+			entities: []string{"textEntityTypeTextURL 0+4 http://example.com/?a=1&b=2", "textEntityTypeMentionName 5+4 123"},
+		},
+		{
+			name:     "quoted attribute values containing >",
+			html:     `<a href="https://x/?a>b">link</a> <a href='https://x/?c>d'>link</a>`,
+			text:     "link link",
+			entities: []string{"textEntityTypeTextURL 0+4 https://x/?a>b", "textEntityTypeTextURL 5+4 https://x/?c>d"},
+		},
+		{
+			name:     "character entities",
+			html:     "a &lt;b&gt; &amp; &quot; &#128512; &#x41;",
+			text:     `a <b> & " 😀 A`,
+			entities: []string{},
+		},
+		{
+			name:     "empty entity is dropped",
+			html:     "a<b></b>b",
+			text:     "ab",
+			entities: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formattedText, err := ParseHTML(test.html)
+			if err != nil {
+				t.Fatalf("ParseHTML(%q) error: %s", test.html, err)
+			}
+
+			if formattedText.Text != test.text {
+				t.Errorf("text = %q, want %q", formattedText.Text, test.text)
+			}
+
+			entities := describe(formattedText.Entities)
+			if !reflect.DeepEqual(entities, test.entities) {
+				t.Errorf("entities = %q, want %q", entities, test.entities)
+			}
+		})
+	}
+}
+
+func TestParseHTMLErrors(t *testing.T) {
+	tests := []struct {
+		html    string
+		message string
+	}{
+		{html: "<b>a</i>", message: "doesn't match"},
+		{html: "<b>a", message: "unclosed tag <b>"},
+		{html: "a < b", message: "unclosed tag"},
+		{html: `<a href="x>a</a>`, message: "unclosed tag"},
+		{html: `<a href="&x;">a</a>`, message: "malformed tag"},
+		{html: "a & b", message: "invalid character entity"},
+		{html: "<u>a</u>", message: "unsupported tag"},
+		{html: "<code><b>a</b></code>", message: "can't be nested"},
+		{html: `<a href="tg://user?id=x">a</a>`, message: "invalid user identifier"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseHTML(test.html)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("ParseHTML(%q) error = %v, want %q", test.html, err, test.message)
+		}
+	}
+}
+
+func TestHTMLRoundTrip(t *testing.T) {
+	tests := []string{
+		"plain text",
+		"😀 <b>bold 😀</b> <i>italic</i>",
+		"<b>a<i>b</i>c</b>",
+		"<code>a &lt; b &amp;&amp; c</code>",
+		"<pre>multi\nline</pre>",
+		`<pre><code class="language-go">fmt.Println(&#34;😀&#34;)</code></pre>`,
+		`<a href="http://example.com/">link</a> and <a href="tg://user?id=123">mention</a>`,
+		"&lt;b&gt;not bold&lt;/b&gt;",
+	}
+
+	for _, html := range tests {
+		formattedText, err := ParseHTML(html)
+		if err != nil {
+			t.Fatalf("ParseHTML(%q) error: %s", html, err)
+		}
+
+		rendered := RenderHTML(formattedText)
+		if rendered != html {
+			t.Errorf("RenderHTML(ParseHTML(%q)) = %q", html, rendered)
+		}
+	}
+}
+
+func TestRenderHTMLOverlapping(t *testing.T) {
+	formattedText := &client.FormattedText{
+		Text:     "ab😀def",
+		Entities: []*client.TextEntity{bold(0, 4), italic(2, 4)},
+	}
+
+	rendered := RenderHTML(formattedText)
+	want := "<b>ab<i>😀</i></b><i>de</i>f"
+	if rendered != want {
+		t.Fatalf("RenderHTML = %q, want %q", rendered, want)
+	}
+
+	parsed, err := ParseHTML(rendered)
+	if err != nil {
+		t.Fatalf("ParseHTML(%q) error: %s", rendered, err)
+	}
+
+	if parsed.Text != formattedText.Text {
+		t.Errorf("text = %q, want %q", parsed.Text, formattedText.Text)
+	}
+
+	// the italic entity is split by the end of the bold one
+	entities := describe(parsed.Entities)
+	wantEntities := []string{"textEntityTypeBold 0+4", "textEntityTypeItalic 2+2", "textEntityTypeItalic 4+2"}
+	if !reflect.DeepEqual(entities, wantEntities) {
+		t.Errorf("entities = %q, want %q", entities, wantEntities)
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		text     string
+		entities []string
+	}{
+		{
+			name:     "surrogate pair before entity",
+			markdown: "😀*b*",
+			text:     "😀b",
+			entities: []string{"textEntityTypeBold 2+1"},
+		},
+		{
+			name:     "entities",
+			markdown: "*bold* _italic_ `code`",
+			text:     "bold italic code",
+			entities: []string{"textEntityTypeBold 0+4", "textEntityTypeItalic 5+6", "textEntityTypeCode 12+4"},
+		},
+		{
+			name:     "pre with language",
+			markdown: "```go\nx := 1```",
+			text:     "x := 1",
+			entities: []string{"textEntityTypePreCode 0+6 go"},
+		},
+		{
+			name:     "links and mentions",
+			markdown: "[link](http://example.com/\\)) [user](tg://user?id=123)",
+			text:     "link user",
+			entities: []string{"textEntityTypeTextURL 0+4 http://example.com/)", "textEntityTypeMentionName 5+4 123"},
+		},
+		{
+			name:     "escapes",
+			markdown: `a\*b\_c\[d\]\\ \x`,
+			text:     `a*b_c[d]\ \x`,
+			entities: []string{},
+		},
+		{
+			name:     "escaped closing character inside entity",
+			markdown: `*a\*b*`,
+			text:     "a*b",
+			entities: []string{"textEntityTypeBold 0+3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formattedText, err := ParseMarkdown(test.markdown)
+			if err != nil {
+				t.Fatalf("ParseMarkdown(%q) error: %s", test.markdown, err)
+			}
+
+			if formattedText.Text != test.text {
+				t.Errorf("text = %q, want %q", formattedText.Text, test.text)
+			}
+
+			entities := describe(formattedText.Entities)
+			if !reflect.DeepEqual(entities, test.entities) {
+				t.Errorf("entities = %q, want %q", entities, test.entities)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		markdown string
+		message  string
+		line     int
+		column   int
+	}{
+		{markdown: "a *b", message: "can't find end", line: 1, column: 3},
+		{markdown: "a\n_b", message: "can't find end", line: 2, column: 1},
+		{markdown: "```a", message: "pre-formatted block", line: 1, column: 1},
+		{markdown: "a]", message: "unexpected", line: 1, column: 2},
+		{markdown: "[a]b", message: "expected (", line: 1, column: 4},
+		{markdown: "[a](tg://user?id=0)", message: "invalid user identifier", line: 1, column: 5},
+	}
+
+	for _, test := range tests {
+		_, err := ParseMarkdown(test.markdown)
+		parseError, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("ParseMarkdown(%q) error = %v, want ParseError", test.markdown, err)
+
+			continue
+		}
+
+		if !strings.Contains(parseError.Message, test.message) || parseError.Line != test.line || parseError.Column != test.column {
+			t.Errorf("ParseMarkdown(%q) error = %s, want %q at line %d, column %d", test.markdown, parseError, test.message, test.line, test.column)
+		}
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	tests := []string{
+		"plain text",
+		"😀 *bold 😀* _italic_",
+		"`a * b`",
+		"```multi\nline```",
+		"```go\nfmt.Println(\"😀\")```",
+		"[link](http://example.com/) and [mention](tg://user?id=123)",
+		`\*not bold\* \[x\]\(y\) \\`,
+	}
+
+	for _, markdown := range tests {
+		formattedText, err := ParseMarkdown(markdown)
+		if err != nil {
+			t.Fatalf("ParseMarkdown(%q) error: %s", markdown, err)
+		}
+
+		rendered := RenderMarkdown(formattedText)
+		if rendered != markdown {
+			t.Errorf("RenderMarkdown(ParseMarkdown(%q)) = %q", markdown, rendered)
+		}
+	}
+}
+
+func TestRenderMarkdownNested(t *testing.T) {
+	// Markdown doesn't support nested entities, so only the outer entity is rendered
+	formattedText := &client.FormattedText{
+		Text:     "a*c",
+		Entities: []*client.TextEntity{bold(0, 3), italic(1, 1)},
+	}
+
+	rendered := RenderMarkdown(formattedText)
+	if rendered != `*a\*c*` {
+		t.Fatalf("RenderMarkdown = %q", rendered)
+	}
+
+	parsed, err := ParseMarkdown(rendered)
+	if err != nil {
+		t.Fatalf("ParseMarkdown(%q) error: %s", rendered, err)
+	}
+
+	if parsed.Text != formattedText.Text || !reflect.DeepEqual(describe(parsed.Entities), []string{"textEntityTypeBold 0+3"}) {
+		t.Errorf("ParseMarkdown(%q) = %q %q", rendered, parsed.Text, describe(parsed.Entities))
+	}
+}
+
+func TestRenderInvalidEntities(t *testing.T) {
+	formattedText := &client.FormattedText{
+		Text: "😀",
+		Entities: []*client.TextEntity{
+			bold(0, 3),
+			italic(-1, 1),
+			nil,
+			{Offset: 0, Length: 2},
+		},
+	}
+
+	if rendered := RenderHTML(formattedText); rendered != "😀" {
+		t.Errorf("RenderHTML = %q", rendered)
+	}
+
+	if rendered := RenderPlain(&client.FormattedText{
+		Text: "see docs",
+		Entities: []*client.TextEntity{
+			{Offset: 4, Length: 4, Type: &client.TextEntityTypeTextURL{URL: "http://example.com/"}},
+		},
+	}); rendered != "see docs (http://example.com/)" {
+		t.Errorf("RenderPlain = %q", rendered)
+	}
+}
@@ -0,0 +1,280 @@
+package markup
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// renderer declares how entities and text are written in a markup language.
+type renderer struct {
+	// open returns markup opening the entity; false if the entity is not rendered
+	open func(entity *client.TextEntity, text string) (string, bool)
+	// close returns markup closing the entity
+	close func(entity *client.TextEntity, text string) string
+	// escape escapes text placed inside the entity, which is nil for text outside of any entity
+	escape func(text string, entity *client.TextEntity) string
+	// nested is true if the markup language supports nested entities
+	nested bool
+}
+
+// render converts formatted text into markup. Overlapping entities are split to keep the markup well-formed.
+func render(formattedText *client.FormattedText, r *renderer) string {
+	if formattedText == nil {
+		return ""
+	}
+
+	text := utf16.Encode([]rune(formattedText.Text))
+
+	entities := make([]*client.TextEntity, 0, len(formattedText.Entities))
+	for _, entity := range formattedText.Entities {
+		if entity == nil || entity.Type == nil || entity.Length <= 0 || entity.Offset < 0 || int(entity.Offset+entity.Length) > len(text) {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		if entities[i].Offset != entities[j].Offset {
+			return entities[i].Offset < entities[j].Offset
+		}
+
+		return entities[i].Length > entities[j].Length
+	})
+
+	substring := func(entity *client.TextEntity) string {
+		return string(utf16.Decode(text[entity.Offset : entity.Offset+entity.Length]))
+	}
+
+	var builder strings.Builder
+	var stack []*client.TextEntity
+	var next int
+
+	top := func() *client.TextEntity {
+		if len(stack) == 0 {
+			return nil
+		}
+
+		return stack[len(stack)-1]
+	}
+
+	position := int32(0)
+	for position <= int32(len(text)) {
+		// close entities ending at the position, reopening inner entities which continue
+		var reopen []*client.TextEntity
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].Offset+stack[i].Length > position {
+				continue
+			}
+
+			for len(stack) > i+1 {
+				inner := top()
+				builder.WriteString(r.close(inner, substring(inner)))
+				stack = stack[:len(stack)-1]
+				if inner.Offset+inner.Length > position {
+					reopen = append([]*client.TextEntity{inner}, reopen...)
+				}
+			}
+
+			builder.WriteString(r.close(stack[i], substring(stack[i])))
+			stack = stack[:i]
+		}
+
+		for _, entity := range reopen {
+			builder.WriteString(mustOpen(r, entity, substring(entity)))
+			stack = append(stack, entity)
+		}
+
+		for next < len(entities) && entities[next].Offset == position {
+			entity := entities[next]
+			next++
+
+			if len(stack) > 0 && !r.nested {
+				continue
+			}
+
+			markup, ok := r.open(entity, substring(entity))
+			if !ok {
+				continue
+			}
+			builder.WriteString(markup)
+			stack = append(stack, entity)
+		}
+
+		if position == int32(len(text)) {
+			break
+		}
+
+		end := int32(len(text))
+		for _, entity := range stack {
+			if entity.Offset+entity.Length < end {
+				end = entity.Offset + entity.Length
+			}
+		}
+		if next < len(entities) && entities[next].Offset < end {
+			end = entities[next].Offset
+		}
+
+		builder.WriteString(r.escape(string(utf16.Decode(text[position:end])), top()))
+		position = end
+	}
+
+	return builder.String()
+}
+
+func mustOpen(r *renderer, entity *client.TextEntity, text string) string {
+	markup, _ := r.open(entity, text)
+
+	return markup
+}
+
+var htmlRenderer = &renderer{
+	open: func(entity *client.TextEntity, text string) (string, bool) {
+		switch t := entity.Type.(type) {
+		case *client.TextEntityTypeBold:
+			return "<b>", true
+		case *client.TextEntityTypeItalic:
+			return "<i>", true
+		case *client.TextEntityTypeCode:
+			return "<code>", true
+		case *client.TextEntityTypePre:
+			return "<pre>", true
+		case *client.TextEntityTypePreCode:
+			return `<pre><code class="language-` + html.EscapeString(t.Language) + `">`, true
+		case *client.TextEntityTypeTextURL:
+			return `<a href="` + html.EscapeString(t.URL) + `">`, true
+		case *client.TextEntityTypeMentionName:
+			return `<a href="` + mentionURLPrefix + strconv.Itoa(int(t.UserID)) + `">`, true
+		case *client.TextEntityTypeURL:
+			return `<a href="` + html.EscapeString(text) + `">`, true
+		case *client.TextEntityTypeEmailAddress:
+			return `<a href="mailto:` + html.EscapeString(text) + `">`, true
+		}
+
+		return "", false
+	},
+	close: func(entity *client.TextEntity, text string) string {
+		switch entity.Type.(type) {
+		case *client.TextEntityTypeBold:
+			return "</b>"
+		case *client.TextEntityTypeItalic:
+			return "</i>"
+		case *client.TextEntityTypeCode:
+			return "</code>"
+		case *client.TextEntityTypePre:
+			return "</pre>"
+		case *client.TextEntityTypePreCode:
+			return "</code></pre>"
+		}
+
+		return "</a>"
+	},
+	escape: func(text string, entity *client.TextEntity) string {
+		return html.EscapeString(text)
+	},
+	nested: true,
+}
+
+var markdownRenderer = &renderer{
+	open: func(entity *client.TextEntity, text string) (string, bool) {
+		switch t := entity.Type.(type) {
+		case *client.TextEntityTypeBold:
+			return "*", true
+		case *client.TextEntityTypeItalic:
+			return "_", true
+		case *client.TextEntityTypeCode:
+			return "`", true
+		case *client.TextEntityTypePre:
+			return "```", true
+		case *client.TextEntityTypePreCode:
+			return "```" + t.Language + "\n", true
+		case *client.TextEntityTypeTextURL, *client.TextEntityTypeMentionName:
+			return "[", true
+		}
+
+		return "", false
+	},
+	close: func(entity *client.TextEntity, text string) string {
+		switch t := entity.Type.(type) {
+		case *client.TextEntityTypeBold:
+			return "*"
+		case *client.TextEntityTypeItalic:
+			return "_"
+		case *client.TextEntityTypeCode:
+			return "`"
+		case *client.TextEntityTypePre, *client.TextEntityTypePreCode:
+			return "```"
+		case *client.TextEntityTypeTextURL:
+			return "](" + escapeMarkdown(t.URL) + ")"
+		case *client.TextEntityTypeMentionName:
+			return "](" + mentionURLPrefix + strconv.Itoa(int(t.UserID)) + ")"
+		}
+
+		return ""
+	},
+	escape: func(text string, entity *client.TextEntity) string {
+		if entity != nil {
+			switch entity.Type.(type) {
+			case *client.TextEntityTypeCode, *client.TextEntityTypePre, *client.TextEntityTypePreCode:
+				return text
+			}
+		}
+
+		return escapeMarkdown(text)
+	},
+}
+
+var plainRenderer = &renderer{
+	open: func(entity *client.TextEntity, text string) (string, bool) {
+		switch entity.Type.(type) {
+		case *client.TextEntityTypeTextURL:
+			return "", true
+		}
+
+		return "", false
+	},
+	close: func(entity *client.TextEntity, text string) string {
+		url := entity.Type.(*client.TextEntityTypeTextURL).URL
+		if url == text {
+			return ""
+		}
+
+		return " (" + url + ")"
+	},
+	escape: func(text string, entity *client.TextEntity) string {
+		return text
+	},
+}
+
+func escapeMarkdown(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(markdownSpecial, r) {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// RenderHTML converts formatted text into HTML understood by ParseHTML.
+// URLs and email addresses are rendered as links.
+func RenderHTML(formattedText *client.FormattedText) string {
+	return render(formattedText, htmlRenderer)
+}
+
+// RenderMarkdown converts formatted text into Markdown understood by ParseMarkdown.
+// Markdown does not support nested entities, so only the outermost entities are rendered.
+func RenderMarkdown(formattedText *client.FormattedText) string {
+	return render(formattedText, markdownRenderer)
+}
+
+// RenderPlain converts formatted text into plain text. URLs of text links are appended in parentheses.
+func RenderPlain(formattedText *client.FormattedText) string {
+	return render(formattedText, plainRenderer)
+}
@@ -0,0 +1,155 @@
+package markup
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// DefaultSummaryTemplates contains default templates of message content summaries by content type.
+// Templates are executed with the content (e.g. *client.MessagePhoto) as data.
+var DefaultSummaryTemplates = map[string]string{
+	client.TypeMessageText:                 `{{plain .Text}}`,
+	client.TypeMessageAnimation:            `[Animation{{with .Animation}} {{duration .Duration}}{{end}}]{{with .Caption}} {{plain .}}{{end}}`,
+	client.TypeMessageAudio:                `[Audio{{with .Audio}}{{if .Performer}} {{.Performer}} -{{end}}{{if .Title}} {{.Title}}{{end}} {{duration .Duration}}{{end}}]{{with .Caption}} {{plain .}}{{end}}`,
+	client.TypeMessageDocument:             `[Document{{with .Document}}{{if .FileName}} {{.FileName}}{{end}}{{with .Document}} {{size .Size}}{{end}}{{end}}]{{with .Caption}} {{plain .}}{{end}}`,
+	client.TypeMessagePhoto:                `[Photo]{{with .Caption}} {{plain .}}{{end}}`,
+	client.TypeMessageExpiredPhoto:         `[Expired photo]`,
+	client.TypeMessageSticker:              `[Sticker{{with .Sticker}}{{if .Emoji}} {{.Emoji}}{{end}}{{end}}]`,
+	client.TypeMessageVideo:                `[Video{{with .Video}} {{duration .Duration}}{{end}}]{{with .Caption}} {{plain .}}{{end}}`,
+	client.TypeMessageExpiredVideo:         `[Expired video]`,
+	client.TypeMessageVideoNote:            `[Video message{{with .VideoNote}} {{duration .Duration}}{{end}}]`,
+	client.TypeMessageVoiceNote:            `[Voice message{{with .VoiceNote}} {{duration .Duration}}{{end}}]{{with .Caption}} {{plain .}}{{end}}`,
+	client.TypeMessageLocation:             `[{{if .LivePeriod}}Live location{{else}}Location{{end}}{{with .Location}} {{.Latitude}}, {{.Longitude}}{{end}}]`,
+	client.TypeMessageVenue:                `[Venue{{with .Venue}} {{.Title}}{{if .Address}}, {{.Address}}{{end}}{{end}}]`,
+	client.TypeMessageContact:              `[Contact{{with .Contact}} {{.FirstName}}{{if .LastName}} {{.LastName}}{{end}}{{if .PhoneNumber}} {{.PhoneNumber}}{{end}}{{end}}]`,
+	client.TypeMessageGame:                 `[Game{{with .Game}} {{.Title}}{{end}}]`,
+	client.TypeMessageInvoice:              `[Invoice {{.Title}} {{amount .TotalAmount}} {{.Currency}}]`,
+	client.TypeMessageCall:                 `[Call {{duration .Duration}}]`,
+	client.TypeMessageBasicGroupChatCreate: `Group "{{.Title}}" created`,
+	client.TypeMessageSupergroupChatCreate: `Supergroup "{{.Title}}" created`,
+	client.TypeMessageChatChangeTitle:      `Chat title changed to "{{.Title}}"`,
+	client.TypeMessageChatChangePhoto:      `Chat photo changed`,
+	client.TypeMessageChatDeletePhoto:      `Chat photo deleted`,
+	client.TypeMessageChatAddMembers:       `Users {{join .MemberUserIDs}} added to the chat`,
+	client.TypeMessageChatJoinByLink:       `User joined the chat by invite link`,
+	client.TypeMessageChatDeleteMember:     `User {{.UserID}} removed from the chat`,
+	client.TypeMessageChatUpgradeTo:        `Group upgraded to supergroup {{.SupergroupID}}`,
+	client.TypeMessageChatUpgradeFrom:      `Supergroup created from group "{{.Title}}"`,
+	client.TypeMessagePinMessage:           `Message {{.MessageID}} pinned`,
+	client.TypeMessageScreenshotTaken:      `Screenshot taken`,
+	client.TypeMessageChatSetTTL:           `Self-destruct timer set to {{duration .TTL}}`,
+	client.TypeMessageCustomServiceAction:  `{{.Text}}`,
+	client.TypeMessageGameScore:            `Game score {{.Score}}`,
+	client.TypeMessagePaymentSuccessful:    `Payment of {{amount .TotalAmount}} {{.Currency}} succeeded`,
+	client.TypeMessagePaymentSuccessfulBot: `Payment of {{amount .TotalAmount}} {{.Currency}} received`,
+	client.TypeMessageContactRegistered:    `Contact joined Telegram`,
+	client.TypeMessageWebsiteConnected:     `Website {{.DomainName}} connected`,
+	client.TypeMessagePassportDataSent:     `Telegram Passport data sent`,
+	client.TypeMessagePassportDataReceived: `Telegram Passport data received`,
+	client.TypeMessageUnsupported:          `[Unsupported message]`,
+}
+
+var summaryFuncs = template.FuncMap{
+	"plain":    RenderPlain,
+	"html":     RenderHTML,
+	"markdown": RenderMarkdown,
+	"duration": func(seconds int32) string {
+		if seconds >= 3600 {
+			return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+		}
+
+		return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+	},
+	"size": func(size int32) string {
+		const unit = 1024
+		if size < unit {
+			return fmt.Sprintf("%d B", size)
+		}
+
+		value, exponent := float64(size)/unit, 0
+		for value >= unit && exponent < 2 {
+			value /= unit
+			exponent++
+		}
+
+		return fmt.Sprintf("%.1f %cB", value, "KMG"[exponent])
+	},
+	"amount": func(amount int64) string {
+		return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+	},
+	"join": func(ids []int32) string {
+		parts := make([]string, len(ids))
+		for i, id := range ids {
+			parts[i] = fmt.Sprint(id)
+		}
+
+		return strings.Join(parts, ", ")
+	},
+}
+
+// Summarizer converts message contents into short human readable summaries using templates.
+// Besides standard functions templates can use plain, html and markdown to render formatted text,
+// duration to format seconds, size to format bytes, amount to format payment amounts and join to list identifiers.
+type Summarizer struct {
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+}
+
+// NewSummarizer creates new summarizer with default templates.
+func NewSummarizer() *Summarizer {
+	summarizer := &Summarizer{
+		templates: map[string]*template.Template{},
+	}
+
+	for contentType, text := range DefaultSummaryTemplates {
+		err := summarizer.SetTemplate(contentType, text)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return summarizer
+}
+
+// SetTemplate replaces template of summaries of the content type (e.g. client.TypeMessagePhoto).
+func (summarizer *Summarizer) SetTemplate(contentType string, text string) error {
+	tmpl, err := template.New(contentType).Funcs(summaryFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	summarizer.mu.Lock()
+	defer summarizer.mu.Unlock()
+
+	summarizer.templates[contentType] = tmpl
+
+	return nil
+}
+
+// Summarize returns summary of the message content.
+func (summarizer *Summarizer) Summarize(content client.MessageContent) (string, error) {
+	if content == nil {
+		return "", nil
+	}
+
+	summarizer.mu.RLock()
+	tmpl, ok := summarizer.templates[content.MessageContentType()]
+	summarizer.mu.RUnlock()
+
+	if !ok {
+		return "[" + content.MessageContentType() + "]", nil
+	}
+
+	var builder strings.Builder
+
+	err := tmpl.Execute(&builder, content)
+	if err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,193 @@
+package netstat
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Sample contains network usage polled at some point in time.
+type Sample struct {
+	// Time of polling
+	At time.Time
+	// Point in time (Unix timestamp) when TDLib began collecting statistics
+	SinceDate int32
+	// Usage reported by TDLib
+	Usage *Usage
+	// Usage since the previous sample
+	Delta *Usage
+}
+
+// Collector polls network usage statistics, computes deltas between polls and accumulates total usage.
+type Collector struct {
+	tdlibClient   *client.Client
+	mu            sync.Mutex
+	interval      time.Duration
+	resetInterval time.Duration
+	onlyCurrent   bool
+	handler       func(*Sample)
+	last          *Sample
+	total         *Usage
+}
+
+// Option is a function type which adjusts collector's configuration.
+type Option func(*Collector)
+
+// WithInterval configures the collector to poll network statistics with specified interval.
+func WithInterval(interval time.Duration) Option {
+	return func(collector *Collector) {
+		if interval > 0 {
+			collector.interval = interval
+		}
+	}
+}
+
+// WithResetInterval configures the collector to reset network statistics in TDLib with specified interval.
+func WithResetInterval(resetInterval time.Duration) Option {
+	return func(collector *Collector) {
+		collector.resetInterval = resetInterval
+	}
+}
+
+// WithOnlyCurrent configures the collector to poll only data for the current library launch.
+func WithOnlyCurrent() Option {
+	return func(collector *Collector) {
+		collector.onlyCurrent = true
+	}
+}
+
+// WithHandler configures the collector to call the handler with every polled sample.
+func WithHandler(handler func(*Sample)) Option {
+	return func(collector *Collector) {
+		collector.handler = handler
+	}
+}
+
+// New creates new network usage collector.
+func New(tdlibClient *client.Client, options ...Option) *Collector {
+	collector := &Collector{
+		tdlibClient: tdlibClient,
+		interval:    time.Minute,
+		total:       newUsage(),
+	}
+
+	for _, option := range options {
+		option(collector)
+	}
+
+	return collector
+}
+
+// Run polls network statistics and resets them with the configured intervals until the context is done.
+func (collector *Collector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(collector.interval)
+	defer ticker.Stop()
+
+	var reset <-chan time.Time
+	if collector.resetInterval > 0 {
+		resetTicker := time.NewTicker(collector.resetInterval)
+		defer resetTicker.Stop()
+
+		reset = resetTicker.C
+	}
+
+	for {
+		_, err := collector.Collect()
+		if err != nil {
+			log.Printf("network statistics error: %s\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+
+		case <-reset:
+			err = collector.Reset()
+			if err != nil {
+				log.Printf("reset network statistics error: %s\n", err)
+			}
+		}
+	}
+}
+
+// Collect polls network statistics and returns the sample with usage since the previous poll.
+// If statistics are reset since the previous poll, the whole polled usage is considered as the delta.
+func (collector *Collector) Collect() (*Sample, error) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	return collector.collect()
+}
+
+// Reset polls network statistics, so no usage is lost, and resets them in TDLib.
+func (collector *Collector) Reset() error {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	_, err := collector.collect()
+	if err != nil {
+		return err
+	}
+
+	_, err = collector.tdlibClient.ResetNetworkStatistics()
+	if err != nil {
+		return err
+	}
+
+	collector.last = nil
+
+	return nil
+}
+
+// Last returns the last polled sample; nil if statistics are not polled yet.
+func (collector *Collector) Last() *Sample {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	return collector.last
+}
+
+// Total returns sum of deltas of all polled samples, including usage reported by the first poll.
+func (collector *Collector) Total() *Usage {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	return collector.total.copy()
+}
+
+// collect polls network statistics. Must be called under the lock.
+func (collector *Collector) collect() (*Sample, error) {
+	statistics, err := collector.tdlibClient.GetNetworkStatistics(&client.GetNetworkStatisticsRequest{
+		OnlyCurrent: collector.onlyCurrent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &Sample{
+		At:        time.Now(),
+		SinceDate: statistics.SinceDate,
+		Usage:     usageOf(statistics),
+	}
+
+	last := collector.last
+	if last != nil && last.SinceDate == sample.SinceDate && sample.Usage.covers(last.Usage) {
+		sample.Delta = sample.Usage.sub(last.Usage)
+	} else {
+		sample.Delta = sample.Usage.copy()
+	}
+
+	collector.last = sample
+	collector.total = collector.total.add(sample.Delta)
+
+	if collector.handler != nil {
+		collector.handler(sample)
+	}
+
+	return sample, nil
+}
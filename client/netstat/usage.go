@@ -0,0 +1,164 @@
+package netstat
+
+import (
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Counter contains numbers of sent and received bytes.
+type Counter struct {
+	SentBytes     int64 `json:"sent_bytes"`
+	ReceivedBytes int64 `json:"received_bytes"`
+}
+
+// Total returns total number of sent and received bytes.
+func (counter Counter) Total() int64 {
+	return counter.SentBytes + counter.ReceivedBytes
+}
+
+func (counter Counter) add(other Counter) Counter {
+	return Counter{
+		SentBytes:     counter.SentBytes + other.SentBytes,
+		ReceivedBytes: counter.ReceivedBytes + other.ReceivedBytes,
+	}
+}
+
+func (counter Counter) sub(other Counter) Counter {
+	return Counter{
+		SentBytes:     counter.SentBytes - other.SentBytes,
+		ReceivedBytes: counter.ReceivedBytes - other.ReceivedBytes,
+	}
+}
+
+// Usage contains network usage split by file types and network types.
+type Usage struct {
+	// Total usage
+	Total Counter `json:"total"`
+	// Usage by file types, keyed by type of FileType, e.g. "fileTypePhoto"
+	FileTypes map[string]Counter `json:"file_types"`
+	// Usage by network types, keyed by type of NetworkType, e.g. "networkTypeWiFi"
+	NetworkTypes map[string]Counter `json:"network_types"`
+	// Usage by calls
+	Calls Counter `json:"calls"`
+	// Total call duration, in seconds
+	CallDuration float64 `json:"call_duration"`
+}
+
+func newUsage() *Usage {
+	return &Usage{
+		FileTypes:    map[string]Counter{},
+		NetworkTypes: map[string]Counter{},
+	}
+}
+
+// usageOf aggregates entries of network statistics.
+func usageOf(statistics *client.NetworkStatistics) *Usage {
+	usage := newUsage()
+
+	for _, entry := range statistics.Entries {
+		switch e := entry.(type) {
+		case *client.NetworkStatisticsEntryFile:
+			counter := Counter{
+				SentBytes:     e.SentBytes,
+				ReceivedBytes: e.ReceivedBytes,
+			}
+
+			usage.Total = usage.Total.add(counter)
+			if e.FileType != nil {
+				key := e.FileType.FileTypeType()
+				usage.FileTypes[key] = usage.FileTypes[key].add(counter)
+			}
+			if e.NetworkType != nil {
+				key := e.NetworkType.NetworkTypeType()
+				usage.NetworkTypes[key] = usage.NetworkTypes[key].add(counter)
+			}
+
+		case *client.NetworkStatisticsEntryCall:
+			counter := Counter{
+				SentBytes:     e.SentBytes,
+				ReceivedBytes: e.ReceivedBytes,
+			}
+
+			usage.Total = usage.Total.add(counter)
+			usage.Calls = usage.Calls.add(counter)
+			usage.CallDuration += e.Duration
+			if e.NetworkType != nil {
+				key := e.NetworkType.NetworkTypeType()
+				usage.NetworkTypes[key] = usage.NetworkTypes[key].add(counter)
+			}
+		}
+	}
+
+	return usage
+}
+
+// add returns sum of the usages.
+func (usage *Usage) add(other *Usage) *Usage {
+	result := usage.copy()
+
+	result.Total = result.Total.add(other.Total)
+	result.Calls = result.Calls.add(other.Calls)
+	result.CallDuration += other.CallDuration
+	for key, counter := range other.FileTypes {
+		result.FileTypes[key] = result.FileTypes[key].add(counter)
+	}
+	for key, counter := range other.NetworkTypes {
+		result.NetworkTypes[key] = result.NetworkTypes[key].add(counter)
+	}
+
+	return result
+}
+
+// sub returns difference of the usages.
+func (usage *Usage) sub(other *Usage) *Usage {
+	result := usage.copy()
+
+	result.Total = result.Total.sub(other.Total)
+	result.Calls = result.Calls.sub(other.Calls)
+	result.CallDuration -= other.CallDuration
+	for key, counter := range other.FileTypes {
+		result.FileTypes[key] = result.FileTypes[key].sub(counter)
+	}
+	for key, counter := range other.NetworkTypes {
+		result.NetworkTypes[key] = result.NetworkTypes[key].sub(counter)
+	}
+
+	return result
+}
+
+// covers returns true if every counter of the usage is not less than the other's one.
+func (usage *Usage) covers(other *Usage) bool {
+	diff := usage.sub(other)
+	if diff.Total.SentBytes < 0 || diff.Total.ReceivedBytes < 0 || diff.Calls.SentBytes < 0 || diff.Calls.ReceivedBytes < 0 {
+		return false
+	}
+
+	for _, counter := range diff.FileTypes {
+		if counter.SentBytes < 0 || counter.ReceivedBytes < 0 {
+			return false
+		}
+	}
+
+	for _, counter := range diff.NetworkTypes {
+		if counter.SentBytes < 0 || counter.ReceivedBytes < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (usage *Usage) copy() *Usage {
+	result := newUsage()
+
+	result.Total = usage.Total
+	result.Calls = usage.Calls
+	result.CallDuration = usage.CallDuration
+	for key, counter := range usage.FileTypes {
+		result.FileTypes[key] = counter
+	}
+	for key, counter := range usage.NetworkTypes {
+		result.NetworkTypes[key] = counter
+	}
+
+	return result
+}
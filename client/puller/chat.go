@@ -1,53 +1,122 @@
 package puller
 
 import (
+	"context"
+
 	"github.com/u-robot/go-tdlib/client"
 )
 
-// ChatHistory returns channels to listen chat messages and errors.
-func ChatHistory(tdlibClient *client.Client, chatID int64) (chan *client.Message, chan error) {
-	messageChan := make(chan *client.Message, 10)
-	errChan := make(chan error, 1)
+// HistoryCursor contains position of a chat history pull.
+type HistoryCursor struct {
+	ChatID int64 `json:"chat_id"`
+	// Identifier of the last delivered message; 0 to start from the newest message
+	FromMessageID int64 `json:"from_message_id"`
+}
 
-	var fromMessageID int64
-	var offset int32
-	var limit int32 = 100
+// messageStream delivers pulled messages.
+type messageStream struct {
+	*pull
+	messages chan *client.Message
+}
 
-	go chatHistory(tdlibClient, messageChan, errChan, chatID, fromMessageID, offset, limit, false)
+func newMessageStream(ctx context.Context, options []Option) *messageStream {
+	return &messageStream{
+		pull:     newPull(ctx, options),
+		messages: make(chan *client.Message),
+	}
+}
 
-	return messageChan, errChan
+// Messages returns channel of pulled messages. The channel is closed when pulling stops.
+func (messageStream *messageStream) Messages() <-chan *client.Message {
+	return messageStream.messages
 }
 
-func chatHistory(tdlibClient *client.Client, messageChan chan *client.Message, errChan chan error, chatID int64, fromMessageID int64, offset int32, limit int32, onlyLocal bool) {
-	defer func() {
-		close(messageChan)
-		close(errChan)
-	}()
+// Next returns the next pulled message. It returns false when pulling stops, see Err for the reason.
+func (messageStream *messageStream) Next() (*client.Message, bool) {
+	message, ok := <-messageStream.messages
 
-	for {
-		messages, err := tdlibClient.GetChatHistory(&client.GetChatHistoryRequest{
-			ChatID:        chatID,
-			FromMessageID: fromMessageID,
-			Offset:        offset,
-			Limit:         limit,
-			OnlyLocal:     onlyLocal,
-		})
-		if err != nil {
-			errChan <- err
+	return message, ok
+}
 
-			return
-		}
+// send delivers the message. It returns false if the context is done before the message is delivered.
+func (messageStream *messageStream) send(ctx context.Context, message interface{}) bool {
+	select {
+	case messageStream.messages <- message.(*client.Message):
+		return true
 
-		if len(messages.Messages) == 0 {
-			errChan <- ErrEndOfPull
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (messageStream *messageStream) close() {
+	close(messageStream.messages)
+}
 
-			break
+// messageItems returns non-nil messages as page items.
+func messageItems(messages []*client.Message) []interface{} {
+	items := make([]interface{}, 0, len(messages))
+	for _, message := range messages {
+		if message != nil {
+			items = append(items, message)
 		}
+	}
 
-		for _, message := range messages.Messages {
-			fromMessageID = message.ID
+	return items
+}
 
-			messageChan <- message
-		}
+// MessagePuller pulls chat history.
+type MessagePuller struct {
+	*messageStream
+}
+
+// Cursor returns position after the last delivered message.
+func (messagePuller *MessagePuller) Cursor() HistoryCursor {
+	return messagePuller.getCursor().(HistoryCursor)
+}
+
+// ChatHistory pulls messages of the chat from the newest to the oldest until the context is done.
+func ChatHistory(ctx context.Context, tdlibClient *client.Client, chatID int64, options ...Option) *MessagePuller {
+	return ChatHistoryFrom(ctx, tdlibClient, HistoryCursor{ChatID: chatID}, options...)
+}
+
+// ChatHistoryFrom pulls messages of the chat from the cursor to the oldest message until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func ChatHistoryFrom(ctx context.Context, tdlibClient *client.Client, cursor HistoryCursor, options ...Option) *MessagePuller {
+	messagePuller := &MessagePuller{
+		messageStream: newMessageStream(ctx, options),
+	}
+
+	messagePuller.run(chatHistoryPaginator(tdlibClient, 0, 100, false), cursor, messagePuller.send, messagePuller.close)
+
+	return messagePuller
+}
+
+func chatHistoryPaginator(tdlibClient *client.Client, offset int32, limit int32, onlyLocal bool) *Paginator {
+	return &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			historyCursor := cursor.(HistoryCursor)
+
+			messages, err := tdlibClient.GetChatHistory(&client.GetChatHistoryRequest{
+				ChatID:        historyCursor.ChatID,
+				FromMessageID: historyCursor.FromMessageID,
+				Offset:        offset,
+				Limit:         limit,
+				OnlyLocal:     onlyLocal,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return &Page{
+				Items: messageItems(messages.Messages),
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			historyCursor := cursor.(HistoryCursor)
+			historyCursor.FromMessageID = page.Items[index].(*client.Message).ID
+
+			return historyCursor
+		},
 	}
 }
@@ -1,63 +1,134 @@
 package puller
 
 import (
+	"context"
 	"math"
 
 	"github.com/u-robot/go-tdlib/client"
 )
 
-// Chats returns channels to listen chats and errors.
-func Chats(tdlibClient *client.Client) (chan *client.Chat, chan error) {
-	chatChan := make(chan *client.Chat, 10)
-	errChan := make(chan error, 1)
+// ChatsCursor contains position of a chat list pull.
+type ChatsCursor struct {
+	// Order of the last delivered chat; math.MaxInt64 to start from the beginning of the chat list
+	OffsetOrder int64 `json:"offset_order"`
+	// Identifier of the last delivered chat
+	OffsetChatID int64 `json:"offset_chat_id"`
+}
 
-	var offsetOrder client.Int64JSON = math.MaxInt64
-	var offsetChatID int64
-	var limit int32 = 100
+// chatStream delivers pulled chats.
+type chatStream struct {
+	*pull
+	chats chan *client.Chat
+}
 
-	go chats(tdlibClient, chatChan, errChan, offsetOrder, offsetChatID, limit)
+func newChatStream(ctx context.Context, options []Option) *chatStream {
+	return &chatStream{
+		pull:  newPull(ctx, options),
+		chats: make(chan *client.Chat),
+	}
+}
 
-	return chatChan, errChan
+// Chats returns channel of pulled chats. The channel is closed when pulling stops.
+func (chatStream *chatStream) Chats() <-chan *client.Chat {
+	return chatStream.chats
 }
 
-func chats(tdlibClient *client.Client, chatChan chan *client.Chat, errChan chan error, offsetOrder client.Int64JSON, offsetChatID int64, limit int32) {
-	defer func() {
-		close(chatChan)
-		close(errChan)
-	}()
+// Next returns the next pulled chat. It returns false when pulling stops, see Err for the reason.
+func (chatStream *chatStream) Next() (*client.Chat, bool) {
+	chat, ok := <-chatStream.chats
 
-	for {
-		chats, err := tdlibClient.GetChats(&client.GetChatsRequest{
-			OffsetOrder:  offsetOrder,
-			OffsetChatID: offsetChatID,
-			Limit:        limit,
-		})
-		if err != nil {
-			errChan <- err
+	return chat, ok
+}
 
-			return
-		}
+// send delivers the chat. It returns false if the context is done before the chat is delivered.
+func (chatStream *chatStream) send(ctx context.Context, chat interface{}) bool {
+	select {
+	case chatStream.chats <- chat.(*client.Chat):
+		return true
 
-		if len(chats.ChatIDs) == 0 {
-			errChan <- ErrEndOfPull
+	case <-ctx.Done():
+		return false
+	}
+}
 
-			break
-		}
+func (chatStream *chatStream) close() {
+	close(chatStream.chats)
+}
+
+// ChatPuller pulls chats.
+type ChatPuller struct {
+	*chatStream
+}
+
+// Cursor returns position after the last delivered chat.
+func (chatPuller *ChatPuller) Cursor() ChatsCursor {
+	return chatPuller.getCursor().(ChatsCursor)
+}
+
+// Chats pulls chats in the order of the chat list until the context is done.
+func Chats(ctx context.Context, tdlibClient *client.Client, options ...Option) *ChatPuller {
+	return ChatsFrom(ctx, tdlibClient, ChatsCursor{OffsetOrder: math.MaxInt64}, options...)
+}
+
+// ChatsFrom pulls chats in the order of the chat list from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func ChatsFrom(ctx context.Context, tdlibClient *client.Client, cursor ChatsCursor, options ...Option) *ChatPuller {
+	chatPuller := &ChatPuller{
+		chatStream: newChatStream(ctx, options),
+	}
+
+	var limit int32 = 100
 
-		for _, chatID := range chats.ChatIDs {
-			chat, err := tdlibClient.GetChat(&client.GetChatRequest{
-				ChatID: chatID,
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			chatsCursor := cursor.(ChatsCursor)
+
+			chats, err := tdlibClient.GetChats(&client.GetChatsRequest{
+				OffsetOrder:  client.Int64JSON(chatsCursor.OffsetOrder),
+				OffsetChatID: chatsCursor.OffsetChatID,
+				Limit:        limit,
 			})
 			if err != nil {
-				errChan <- err
+				return nil, err
+			}
+
+			items, err := chatItems(tdlibClient, chats.ChatIDs)
+			if err != nil {
+				return nil, err
+			}
+
+			return &Page{
+				Items: items,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			chat := page.Items[index].(*client.Chat)
 
-				return
+			return ChatsCursor{
+				OffsetOrder:  int64(chat.Order),
+				OffsetChatID: chat.ID,
 			}
+		},
+	}
 
-			offsetOrder = chat.Order
-			offsetChatID = chat.ID
+	chatPuller.run(paginator, cursor, chatPuller.send, chatPuller.close)
+
+	return chatPuller
+}
 
-			chatChan <- chat
+// chatItems returns the chats as page items.
+func chatItems(tdlibClient *client.Client, chatIDs []int64) ([]interface{}, error) {
+	items := make([]interface{}, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		chat, err := tdlibClient.GetChat(&client.GetChatRequest{
+			ChatID: chatID,
+		})
+		if err != nil {
+			return nil, err
 		}
+
+		items = append(items, chat)
 	}
+
+	return items, nil
 }
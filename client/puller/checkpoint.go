@@ -0,0 +1,131 @@
+package puller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Store is interface declaring persistence of puller cursors.
+type Store interface {
+	// Load loads the cursor stored by the key. It returns false if there is no cursor.
+	Load(key string, cursor interface{}) (bool, error)
+	// Save stores the cursor by the key.
+	Save(key string, cursor interface{}) error
+}
+
+// MemoryStore implements Store interface keeping cursors in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	cursors map[string][]byte
+}
+
+// NewMemoryStore creates new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		cursors: map[string][]byte{},
+	}
+}
+
+// Load loads the cursor stored by the key.
+func (store *MemoryStore) Load(key string, cursor interface{}) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, ok := store.cursors[key]
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, cursor)
+}
+
+// Save stores the cursor by the key.
+func (store *MemoryStore) Save(key string, cursor interface{}) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.cursors[key] = data
+
+	return nil
+}
+
+// FileStore implements Store interface keeping every cursor in a separate JSON file of the directory.
+type FileStore struct {
+	mu        sync.Mutex
+	directory string
+}
+
+// NewFileStore creates new instance of FileStore creating the directory if it does not exist.
+func NewFileStore(directory string) (*FileStore, error) {
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		directory: directory,
+	}, nil
+}
+
+// Load loads the cursor stored by the key.
+func (store *FileStore) Load(key string, cursor interface{}) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := ioutil.ReadFile(store.path(key))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, cursor)
+}
+
+// Save stores the cursor by the key.
+func (store *FileStore) Save(key string, cursor interface{}) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	path := store.path(key)
+
+	err = ioutil.WriteFile(path+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func (store *FileStore) path(key string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key)
+
+	return filepath.Join(store.directory, name+".json")
+}
+
+// Option is a function type which adjusts puller's configuration.
+type Option func(*pull)
+
+// WithCheckpoint configures the puller to resume from the cursor stored in the store by the key
+// and to save the cursor of delivered data after every pulled page and when pulling stops.
+func WithCheckpoint(store Store, key string) Option {
+	return func(pull *pull) {
+		pull.store = store
+		pull.key = key
+	}
+}
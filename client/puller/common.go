@@ -0,0 +1,73 @@
+package puller
+
+import (
+	"context"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// GroupsInCommonCursor contains position of a pull of groups in common with a user.
+type GroupsInCommonCursor struct {
+	UserID int32 `json:"user_id"`
+	// Identifier of the last delivered chat; 0 to start from the beginning
+	OffsetChatID int64 `json:"offset_chat_id"`
+}
+
+// CommonGroupPuller pulls groups in common with a user.
+type CommonGroupPuller struct {
+	*chatStream
+}
+
+// Cursor returns position after the last delivered chat.
+func (commonGroupPuller *CommonGroupPuller) Cursor() GroupsInCommonCursor {
+	return commonGroupPuller.getCursor().(GroupsInCommonCursor)
+}
+
+// GroupsInCommon pulls groups and channels in common with the user until the context is done.
+func GroupsInCommon(ctx context.Context, tdlibClient *client.Client, userID int32, options ...Option) *CommonGroupPuller {
+	return GroupsInCommonFrom(ctx, tdlibClient, GroupsInCommonCursor{UserID: userID}, options...)
+}
+
+// GroupsInCommonFrom pulls groups and channels in common with the user from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func GroupsInCommonFrom(ctx context.Context, tdlibClient *client.Client, cursor GroupsInCommonCursor, options ...Option) *CommonGroupPuller {
+	commonGroupPuller := &CommonGroupPuller{
+		chatStream: newChatStream(ctx, options),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			groupsInCommonCursor := cursor.(GroupsInCommonCursor)
+
+			chats, err := tdlibClient.GetGroupsInCommon(&client.GetGroupsInCommonRequest{
+				UserID:       groupsInCommonCursor.UserID,
+				OffsetChatID: groupsInCommonCursor.OffsetChatID,
+				Limit:        limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			items, err := chatItems(tdlibClient, chats.ChatIDs)
+			if err != nil {
+				return nil, err
+			}
+
+			return &Page{
+				Items: items,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			groupsInCommonCursor := cursor.(GroupsInCommonCursor)
+			groupsInCommonCursor.OffsetChatID = page.Items[index].(*client.Chat).ID
+
+			return groupsInCommonCursor
+		},
+	}
+
+	commonGroupPuller.run(paginator, cursor, commonGroupPuller.send, commonGroupPuller.close)
+
+	return commonGroupPuller
+}
@@ -0,0 +1,115 @@
+package puller
+
+import (
+	"context"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// ContactsCursor contains position of a contacts pull.
+type ContactsCursor struct {
+	// Number of delivered contacts
+	Offset int32 `json:"offset"`
+}
+
+// ContactPuller pulls contacts.
+type ContactPuller struct {
+	*pull
+	users chan *client.User
+}
+
+// Users returns channel of pulled contacts. The channel is closed when pulling stops.
+func (contactPuller *ContactPuller) Users() <-chan *client.User {
+	return contactPuller.users
+}
+
+// Next returns the next pulled contact. It returns false when pulling stops, see Err for the reason.
+func (contactPuller *ContactPuller) Next() (*client.User, bool) {
+	user, ok := <-contactPuller.users
+
+	return user, ok
+}
+
+// Cursor returns position after the last delivered contact.
+func (contactPuller *ContactPuller) Cursor() ContactsCursor {
+	return contactPuller.getCursor().(ContactsCursor)
+}
+
+// send delivers the contact. It returns false if the context is done before the contact is delivered.
+func (contactPuller *ContactPuller) send(ctx context.Context, user interface{}) bool {
+	select {
+	case contactPuller.users <- user.(*client.User):
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Contacts pulls users of the contact list until the context is done.
+func Contacts(ctx context.Context, tdlibClient *client.Client, options ...Option) *ContactPuller {
+	return ContactsFrom(ctx, tdlibClient, ContactsCursor{}, options...)
+}
+
+// ContactsFrom pulls users of the contact list from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func ContactsFrom(ctx context.Context, tdlibClient *client.Client, cursor ContactsCursor, options ...Option) *ContactPuller {
+	contactPuller := &ContactPuller{
+		pull:  newPull(ctx, options),
+		users: make(chan *client.User),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			contactsCursor := cursor.(ContactsCursor)
+
+			// the contact list is returned at once, users are requested page by page
+			contacts, err := tdlibClient.GetContacts()
+			if err != nil {
+				return nil, err
+			}
+
+			userIDs := contacts.UserIDs
+			if int(contactsCursor.Offset) >= len(userIDs) {
+				return &Page{
+					Last: true,
+				}, nil
+			}
+
+			userIDs = userIDs[contactsCursor.Offset:]
+			if len(userIDs) > int(limit) {
+				userIDs = userIDs[:limit]
+			}
+
+			items := make([]interface{}, 0, len(userIDs))
+			for _, userID := range userIDs {
+				user, err := tdlibClient.GetUser(&client.GetUserRequest{
+					UserID: userID,
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				items = append(items, user)
+			}
+
+			return &Page{
+				Items: items,
+				Last:  int(contactsCursor.Offset)+len(items) >= len(contacts.UserIDs),
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			return ContactsCursor{
+				Offset: page.Cursor.(ContactsCursor).Offset + int32(index) + 1,
+			}
+		},
+	}
+
+	contactPuller.run(paginator, cursor, contactPuller.send, func() {
+		close(contactPuller.users)
+	})
+
+	return contactPuller
+}
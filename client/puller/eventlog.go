@@ -0,0 +1,104 @@
+package puller
+
+import (
+	"context"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// EventLogCursor contains position of a chat event log pull.
+type EventLogCursor struct {
+	ChatID int64 `json:"chat_id"`
+	// Search query by which to filter events
+	Query string `json:"query,omitempty"`
+	// Types of events to return; nil to return all events
+	Filters *client.ChatEventLogFilters `json:"filters,omitempty"`
+	// Only events of the users are returned; empty for events of all users
+	UserIDs []int32 `json:"user_ids,omitempty"`
+	// Identifier of the last delivered event; 0 to start from the newest event
+	FromEventID int64 `json:"from_event_id"`
+}
+
+// ChatEventPuller pulls chat events.
+type ChatEventPuller struct {
+	*pull
+	events chan *client.ChatEvent
+}
+
+// Events returns channel of pulled chat events. The channel is closed when pulling stops.
+func (chatEventPuller *ChatEventPuller) Events() <-chan *client.ChatEvent {
+	return chatEventPuller.events
+}
+
+// Next returns the next pulled chat event. It returns false when pulling stops, see Err for the reason.
+func (chatEventPuller *ChatEventPuller) Next() (*client.ChatEvent, bool) {
+	event, ok := <-chatEventPuller.events
+
+	return event, ok
+}
+
+// Cursor returns position after the last delivered chat event.
+func (chatEventPuller *ChatEventPuller) Cursor() EventLogCursor {
+	return chatEventPuller.getCursor().(EventLogCursor)
+}
+
+// send delivers the chat event. It returns false if the context is done before the chat event is delivered.
+func (chatEventPuller *ChatEventPuller) send(ctx context.Context, event interface{}) bool {
+	select {
+	case chatEventPuller.events <- event.(*client.ChatEvent):
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ChatEventLog pulls events of the supergroup or channel event log from the cursor to the oldest event until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one. Administrator rights are required.
+func ChatEventLog(ctx context.Context, tdlibClient *client.Client, cursor EventLogCursor, options ...Option) *ChatEventPuller {
+	chatEventPuller := &ChatEventPuller{
+		pull:   newPull(ctx, options),
+		events: make(chan *client.ChatEvent),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			eventLogCursor := cursor.(EventLogCursor)
+
+			chatEvents, err := tdlibClient.GetChatEventLog(&client.GetChatEventLogRequest{
+				ChatID:      eventLogCursor.ChatID,
+				Query:       eventLogCursor.Query,
+				FromEventID: client.Int64JSON(eventLogCursor.FromEventID),
+				Limit:       limit,
+				Filters:     eventLogCursor.Filters,
+				UserIDs:     eventLogCursor.UserIDs,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			items := make([]interface{}, len(chatEvents.Events))
+			for i, event := range chatEvents.Events {
+				items[i] = event
+			}
+
+			return &Page{
+				Items: items,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			eventLogCursor := cursor.(EventLogCursor)
+			eventLogCursor.FromEventID = int64(page.Items[index].(*client.ChatEvent).ID)
+
+			return eventLogCursor
+		},
+	}
+
+	chatEventPuller.run(paginator, cursor, chatEventPuller.send, func() {
+		close(chatEventPuller.events)
+	})
+
+	return chatEventPuller
+}
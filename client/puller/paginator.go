@@ -0,0 +1,320 @@
+package puller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Page is a page of pulled items.
+type Page struct {
+	// Cursor which the page is fetched with; set by the paginator
+	Cursor interface{}
+	// Items of the page; nil items are not delivered, but the cursor is advanced over them
+	Items []interface{}
+	// Cursor of the next page; nil to advance the cursor over all items of the page
+	Next interface{}
+	// True, if there are no more pages. Pulling also stops on a page without items and the next cursor
+	Last bool
+}
+
+// isLast returns true if there are no pages after the page.
+func (page *Page) isLast() bool {
+	return page.Last || (len(page.Items) == 0 && page.Next == nil)
+}
+
+// FetchFunc fetches the page at the cursor.
+type FetchFunc func(ctx context.Context, cursor interface{}) (*Page, error)
+
+// AdvanceFunc returns position after the item of the page with the index, given the position before the item.
+type AdvanceFunc func(cursor interface{}, page *Page, index int) interface{}
+
+// Paginator describes how to pull pages of items.
+type Paginator struct {
+	Fetch   FetchFunc
+	Advance AdvanceFunc
+}
+
+// next returns cursor of the page after the page.
+func (paginator *Paginator) next(page *Page) interface{} {
+	if page.Next != nil {
+		return page.Next
+	}
+
+	cursor := page.Cursor
+	for i := range page.Items {
+		cursor = paginator.Advance(cursor, page, i)
+	}
+
+	return cursor
+}
+
+// WithRateLimit configures the puller to fetch pages not more often than once per interval.
+func WithRateLimit(interval time.Duration) Option {
+	return func(pull *pull) {
+		pull.interval = interval
+	}
+}
+
+// WithFloodRetries configures the puller to retry fetching of a page the number of times if TDLib reports flood error,
+// waiting the requested delay before every retry. A page is retried 3 times by default.
+func WithFloodRetries(retries int) Option {
+	return func(pull *pull) {
+		if retries >= 0 {
+			pull.retries = retries
+		}
+	}
+}
+
+// WithMaxItems configures the puller to stop after delivering the number of items.
+func WithMaxItems(maxItems int) Option {
+	return func(pull *pull) {
+		pull.maxItems = maxItems
+	}
+}
+
+// WithPrefetch configures the puller to fetch up to the number of pages ahead of the delivered items.
+func WithPrefetch(pages int) Option {
+	return func(pull *pull) {
+		pull.prefetch = pages
+	}
+}
+
+// FloodWait returns delay requested by TDLib flood error. It returns false if the error is not a flood error.
+func FloodWait(err error) (time.Duration, bool) {
+	responseError, ok := err.(client.ResponseError)
+	if !ok || responseError.Err == nil || responseError.Err.Code != 429 {
+		return 0, false
+	}
+
+	// message looks like "Too Many Requests: retry after 10"
+	message := responseError.Err.Message
+	index := strings.LastIndex(message, "retry after ")
+	if index < 0 {
+		return time.Second, true
+	}
+
+	seconds, err := strconv.Atoi(strings.TrimSpace(message[index+len("retry after "):]))
+	if err != nil || seconds <= 0 {
+		return time.Second, true
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// pageResult is a fetched page or the error of fetching.
+type pageResult struct {
+	page *Page
+	err  error
+}
+
+// paginate fetches pages of the paginator from the current cursor and delivers their items
+// until there are no more pages, the limit of items is reached or the context is done.
+// The cursor is updated after every item and saved after every page.
+func (pull *pull) paginate(ctx context.Context, paginator *Paginator, deliver func(ctx context.Context, item interface{}) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var next func() (*Page, error)
+
+	if pull.prefetch > 0 {
+		results := make(chan pageResult, pull.prefetch)
+
+		go pull.prefetchPages(ctx, paginator, pull.getCursor(), results)
+
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		next = func() (*Page, error) {
+			result, ok := <-results
+			if !ok {
+				return nil, ctx.Err()
+			}
+
+			return result.page, result.err
+		}
+	} else {
+		var page *Page
+		next = func() (*Page, error) {
+			cursor := pull.getCursor()
+			if page != nil {
+				cursor = paginator.next(page)
+			}
+
+			var err error
+			page, err = pull.fetch(ctx, paginator, cursor)
+
+			return page, err
+		}
+	}
+
+	delivered := 0
+
+	for {
+		page, err := next()
+		if err != nil {
+			return err
+		}
+
+		cursor := pull.getCursor()
+
+		for i, item := range page.Items {
+			if item != nil {
+				if !deliver(ctx, item) {
+					return ctx.Err()
+				}
+				delivered++
+			}
+
+			cursor = paginator.Advance(cursor, page, i)
+			pull.setCursor(cursor)
+
+			if pull.maxItems > 0 && delivered >= pull.maxItems {
+				return nil
+			}
+		}
+
+		if page.Next != nil {
+			cursor = page.Next
+			pull.setCursor(cursor)
+		}
+
+		err = pull.save(cursor)
+		if err != nil {
+			return err
+		}
+
+		if page.isLast() {
+			return nil
+		}
+	}
+}
+
+// prefetchPages fetches pages from the cursor into the channel until there are no more pages,
+// fetching fails or the context is done. The channel is closed when fetching stops.
+func (pull *pull) prefetchPages(ctx context.Context, paginator *Paginator, cursor interface{}, results chan<- pageResult) {
+	defer close(results)
+
+	for {
+		page, err := pull.fetch(ctx, paginator, cursor)
+
+		select {
+		case results <- pageResult{page: page, err: err}:
+		case <-ctx.Done():
+			return
+		}
+
+		if err != nil || page.isLast() {
+			return
+		}
+
+		cursor = paginator.next(page)
+	}
+}
+
+// fetch fetches the page at the cursor respecting the rate limit and retrying on flood errors.
+// Pages are fetched by a single goroutine at a time.
+func (pull *pull) fetch(ctx context.Context, paginator *Paginator, cursor interface{}) (*Page, error) {
+	for attempt := 0; ; attempt++ {
+		if pull.interval > 0 && !pull.lastFetch.IsZero() {
+			err := sleep(ctx, pull.interval-time.Since(pull.lastFetch))
+			if err != nil {
+				return nil, err
+			}
+		}
+		pull.lastFetch = time.Now()
+
+		page, err := paginator.Fetch(ctx, cursor)
+		if err == nil {
+			if page == nil {
+				page = &Page{
+					Last: true,
+				}
+			}
+			page.Cursor = cursor
+
+			return page, nil
+		}
+
+		delay, ok := FloodWait(err)
+		if !ok || attempt >= pull.retries {
+			return nil, err
+		}
+
+		err = sleep(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for the duration. It returns context error if the context is done earlier.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Puller pulls items of any kind with a paginator.
+type Puller struct {
+	*pull
+	items chan interface{}
+}
+
+// Items returns channel of pulled items. The channel is closed when pulling stops.
+func (puller *Puller) Items() <-chan interface{} {
+	return puller.items
+}
+
+// Next returns the next pulled item. It returns false when pulling stops, see Err for the reason.
+func (puller *Puller) Next() (interface{}, bool) {
+	item, ok := <-puller.items
+
+	return item, ok
+}
+
+// Cursor returns position after the last delivered item.
+func (puller *Puller) Cursor() interface{} {
+	return puller.getCursor()
+}
+
+// New pulls items of the paginator from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one; it is decoded into a value of the cursor's type,
+// so the passed cursor must not be nil if the checkpoint store is configured.
+func New(ctx context.Context, paginator *Paginator, cursor interface{}, options ...Option) *Puller {
+	puller := &Puller{
+		pull:  newPull(ctx, options),
+		items: make(chan interface{}),
+	}
+
+	puller.run(paginator, cursor, func(ctx context.Context, item interface{}) bool {
+		select {
+		case puller.items <- item:
+			return true
+
+		case <-ctx.Done():
+			return false
+		}
+	}, func() {
+		close(puller.items)
+	})
+
+	return puller
+}
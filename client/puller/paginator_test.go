@@ -0,0 +1,242 @@
+package puller
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// numbers returns paginator of numbers from 0 to total-1 with pages of the size; total < 0 means infinite numbers.
+// The cursor is the next number. Every fetched cursor is passed to the fetched function if it is not nil.
+func numbers(total int, size int, fetched func(cursor int)) *Paginator {
+	return &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			start := cursor.(int)
+			if fetched != nil {
+				fetched(start)
+			}
+
+			var items []interface{}
+			for i := start; i < start+size && (total < 0 || i < total); i++ {
+				items = append(items, i)
+			}
+
+			return &Page{
+				Items: items,
+				Last:  total >= 0 && start+size >= total,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			return page.Cursor.(int) + index + 1
+		},
+	}
+}
+
+// collect returns all items of the puller.
+func collect(t *testing.T, puller *Puller) []interface{} {
+	items := []interface{}{}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case item, ok := <-puller.Items():
+			if !ok {
+				return items
+			}
+			items = append(items, item)
+
+		case <-timeout:
+			t.Fatalf("puller is not stopped, pulled %v", items)
+		}
+	}
+}
+
+func floodError(message string) error {
+	return client.ResponseError{
+		Err: &client.Error{
+			Code:    429,
+			Message: message,
+		},
+	}
+}
+
+func TestFloodWait(t *testing.T) {
+	tests := []struct {
+		err   error
+		delay time.Duration
+		ok    bool
+	}{
+		{err: floodError("Too Many Requests: retry after 10"), delay: 10 * time.Second, ok: true},
+		{err: floodError("Too Many Requests"), delay: time.Second, ok: true},
+		{err: floodError("Too Many Requests: retry after x"), delay: time.Second, ok: true},
+		{err: floodError("Too Many Requests: retry after 0"), delay: time.Second, ok: true},
+		{err: client.ResponseError{Err: &client.Error{Code: 400, Message: "Bad Request"}}, delay: 0, ok: false},
+		{err: context.Canceled, delay: 0, ok: false},
+	}
+
+	for _, test := range tests {
+		delay, ok := FloodWait(test.err)
+		if delay != test.delay || ok != test.ok {
+			t.Errorf("FloodWait(%v) = %s, %t, want %s, %t", test.err, delay, ok, test.delay, test.ok)
+		}
+	}
+}
+
+func TestFloodRetry(t *testing.T) {
+	paginator := numbers(2, 2, nil)
+	fetch := paginator.Fetch
+
+	attempts := 0
+	paginator.Fetch = func(ctx context.Context, cursor interface{}) (*Page, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, floodError("Too Many Requests: retry after 1")
+		}
+
+		return fetch(ctx, cursor)
+	}
+
+	started := time.Now()
+	puller := New(context.Background(), paginator, 0)
+	items := collect(t, puller)
+
+	if elapsed := time.Since(started); elapsed < time.Second {
+		t.Errorf("page is retried after %s, want the requested delay of 1s", elapsed)
+	}
+
+	if !reflect.DeepEqual(items, []interface{}{0, 1}) || puller.Err() != nil {
+		t.Errorf("pulled %v with error %v, want [0 1]", items, puller.Err())
+	}
+
+	if attempts != 2 {
+		t.Errorf("page is fetched %d times, want 2", attempts)
+	}
+}
+
+func TestFloodRetriesExceeded(t *testing.T) {
+	paginator := numbers(2, 2, nil)
+	paginator.Fetch = func(ctx context.Context, cursor interface{}) (*Page, error) {
+		return nil, floodError("Too Many Requests: retry after 1")
+	}
+
+	puller := New(context.Background(), paginator, 0, WithFloodRetries(0))
+	items := collect(t, puller)
+
+	if _, ok := FloodWait(puller.Err()); len(items) != 0 || !ok {
+		t.Errorf("pulled %v with error %v, want flood error", items, puller.Err())
+	}
+}
+
+func TestMaxItems(t *testing.T) {
+	for _, prefetch := range []int{0, 2} {
+		puller := New(context.Background(), numbers(-1, 3, nil), 0, WithMaxItems(5), WithPrefetch(prefetch))
+		items := collect(t, puller)
+
+		if !reflect.DeepEqual(items, []interface{}{0, 1, 2, 3, 4}) || puller.Err() != nil {
+			t.Errorf("prefetch %d: pulled %v with error %v, want [0 1 2 3 4]", prefetch, items, puller.Err())
+		}
+
+		if cursor := puller.Cursor(); cursor != 5 {
+			t.Errorf("prefetch %d: cursor = %v, want 5", prefetch, cursor)
+		}
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	store := NewMemoryStore()
+
+	puller := New(context.Background(), numbers(10, 3, nil), 0, WithCheckpoint(store, "numbers"), WithMaxItems(4))
+	items := collect(t, puller)
+	if !reflect.DeepEqual(items, []interface{}{0, 1, 2, 3}) {
+		t.Fatalf("first pull: pulled %v, want [0 1 2 3]", items)
+	}
+
+	var fetched []int
+	puller = New(context.Background(), numbers(10, 3, func(cursor int) {
+		fetched = append(fetched, cursor)
+	}), 0, WithCheckpoint(store, "numbers"))
+	items = collect(t, puller)
+
+	if !reflect.DeepEqual(items, []interface{}{4, 5, 6, 7, 8, 9}) || puller.Err() != nil {
+		t.Errorf("resumed pull: pulled %v with error %v, want [4 5 6 7 8 9]", items, puller.Err())
+	}
+
+	if !reflect.DeepEqual(fetched, []int{4, 7}) {
+		t.Errorf("resumed pull: fetched pages %v, want [4 7]", fetched)
+	}
+
+	var cursor int
+	ok, err := store.Load("numbers", &cursor)
+	if !ok || err != nil || cursor != 10 {
+		t.Errorf("stored cursor = %d, %t, %v, want 10", cursor, ok, err)
+	}
+}
+
+func TestNilCursorWithCheckpoint(t *testing.T) {
+	puller := New(context.Background(), numbers(10, 3, nil), nil, WithCheckpoint(NewMemoryStore(), "numbers"))
+	items := collect(t, puller)
+
+	if len(items) != 0 || puller.Err() != ErrNilCursor {
+		t.Errorf("pulled %v with error %v, want %v", items, puller.Err(), ErrNilCursor)
+	}
+}
+
+func TestCloseDuringPrefetch(t *testing.T) {
+	var mu sync.Mutex
+	fetched := 0
+
+	puller := New(context.Background(), numbers(-1, 3, func(cursor int) {
+		mu.Lock()
+		fetched++
+		mu.Unlock()
+	}), 0, WithPrefetch(2))
+
+	item, ok := puller.Next()
+	if !ok || item != 0 {
+		t.Fatalf("Next() = %v, %t, want 0", item, ok)
+	}
+
+	// wait until the prefetching goroutine is blocked on the full channel of pages
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := fetched
+		mu.Unlock()
+
+		if n >= 4 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("fetched %d pages, want prefetching of 3 pages after the delivered one", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		puller.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close is blocked")
+	}
+
+	if puller.Err() != nil {
+		t.Errorf("Err() = %v, want nil after Close", puller.Err())
+	}
+
+	if cursor := puller.Cursor(); cursor != 1 {
+		t.Errorf("cursor = %v, want 1", cursor)
+	}
+
+	for range puller.Items() {
+	}
+}
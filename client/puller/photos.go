@@ -0,0 +1,101 @@
+package puller
+
+import (
+	"context"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// ProfilePhotosCursor contains position of a user profile photos pull.
+type ProfilePhotosCursor struct {
+	UserID int32 `json:"user_id"`
+	// Number of delivered photos
+	Offset int32 `json:"offset"`
+}
+
+// PhotoPuller pulls photos.
+type PhotoPuller struct {
+	*pull
+	photos chan *client.Photo
+}
+
+// Photos returns channel of pulled photos. The channel is closed when pulling stops.
+func (photoPuller *PhotoPuller) Photos() <-chan *client.Photo {
+	return photoPuller.photos
+}
+
+// Next returns the next pulled photo. It returns false when pulling stops, see Err for the reason.
+func (photoPuller *PhotoPuller) Next() (*client.Photo, bool) {
+	photo, ok := <-photoPuller.photos
+
+	return photo, ok
+}
+
+// Cursor returns position after the last delivered photo.
+func (photoPuller *PhotoPuller) Cursor() ProfilePhotosCursor {
+	return photoPuller.getCursor().(ProfilePhotosCursor)
+}
+
+// send delivers the photo. It returns false if the context is done before the photo is delivered.
+func (photoPuller *PhotoPuller) send(ctx context.Context, photo interface{}) bool {
+	select {
+	case photoPuller.photos <- photo.(*client.Photo):
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// UserProfilePhotos pulls profile photos of the user from the newest to the oldest until the context is done.
+func UserProfilePhotos(ctx context.Context, tdlibClient *client.Client, userID int32, options ...Option) *PhotoPuller {
+	return UserProfilePhotosFrom(ctx, tdlibClient, ProfilePhotosCursor{UserID: userID}, options...)
+}
+
+// UserProfilePhotosFrom pulls profile photos of the user from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func UserProfilePhotosFrom(ctx context.Context, tdlibClient *client.Client, cursor ProfilePhotosCursor, options ...Option) *PhotoPuller {
+	photoPuller := &PhotoPuller{
+		pull:   newPull(ctx, options),
+		photos: make(chan *client.Photo),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			profilePhotosCursor := cursor.(ProfilePhotosCursor)
+
+			userProfilePhotos, err := tdlibClient.GetUserProfilePhotos(&client.GetUserProfilePhotosRequest{
+				UserID: profilePhotosCursor.UserID,
+				Offset: profilePhotosCursor.Offset,
+				Limit:  limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			items := make([]interface{}, len(userProfilePhotos.Photos))
+			for i, photo := range userProfilePhotos.Photos {
+				items[i] = photo
+			}
+
+			return &Page{
+				Items: items,
+				Last:  profilePhotosCursor.Offset+int32(len(items)) >= userProfilePhotos.TotalCount,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			profilePhotosCursor := cursor.(ProfilePhotosCursor)
+			profilePhotosCursor.Offset = page.Cursor.(ProfilePhotosCursor).Offset + int32(index) + 1
+
+			return profilePhotosCursor
+		},
+	}
+
+	photoPuller.run(paginator, cursor, photoPuller.send, func() {
+		close(photoPuller.photos)
+	})
+
+	return photoPuller
+}
@@ -0,0 +1,148 @@
+package puller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrNilCursor is error returned when a puller with a checkpoint store is started from nil cursor.
+var ErrNilCursor = errors.New("cursor is nil")
+
+// pull contains state shared by all pullers: cancellation, configuration, the cursor and the result of pulling.
+type pull struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	mu       sync.Mutex
+	closed   bool
+	err      error
+	cursor   interface{}
+	store    Store
+	key      string
+	interval time.Duration
+	retries  int
+	maxItems int
+	prefetch int
+	// time of the last page fetch used by the rate limit
+	lastFetch time.Time
+}
+
+func newPull(ctx context.Context, options []Option) *pull {
+	ctx, cancel := context.WithCancel(ctx)
+
+	pull := &pull{
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		retries: 3,
+	}
+
+	for _, option := range options {
+		option(pull)
+	}
+
+	return pull
+}
+
+// load loads the cursor from the checkpoint store if it is configured.
+// It returns the passed cursor if there is no stored one. The passed cursor must not be nil as it defines type of the stored one.
+func (pull *pull) load(cursor interface{}) (interface{}, error) {
+	if pull.store == nil {
+		return cursor, nil
+	}
+
+	if cursor == nil {
+		return nil, ErrNilCursor
+	}
+
+	value := reflect.New(reflect.TypeOf(cursor))
+	value.Elem().Set(reflect.ValueOf(cursor))
+
+	ok, err := pull.store.Load(pull.key, value.Interface())
+	if err != nil || !ok {
+		return cursor, err
+	}
+
+	return value.Elem().Interface(), nil
+}
+
+// save saves the cursor to the checkpoint store if it is configured.
+func (pull *pull) save(cursor interface{}) error {
+	if pull.store == nil {
+		return nil
+	}
+
+	return pull.store.Save(pull.key, cursor)
+}
+
+// getCursor returns position after the last delivered item.
+func (pull *pull) getCursor() interface{} {
+	pull.mu.Lock()
+	defer pull.mu.Unlock()
+
+	return pull.cursor
+}
+
+func (pull *pull) setCursor(cursor interface{}) {
+	pull.mu.Lock()
+	pull.cursor = cursor
+	pull.mu.Unlock()
+}
+
+// Done returns channel which is closed when pulling stops.
+func (pull *pull) Done() <-chan struct{} {
+	return pull.done
+}
+
+// Err returns error which stopped pulling. It returns nil if all data is pulled, the limit of items is reached
+// or the puller is closed. It returns context error if the context is done before all data is pulled.
+func (pull *pull) Err() error {
+	pull.mu.Lock()
+	defer pull.mu.Unlock()
+
+	return pull.err
+}
+
+// Close stops pulling and waits until the puller's goroutine exits.
+func (pull *pull) Close() {
+	pull.mu.Lock()
+	pull.closed = true
+	pull.mu.Unlock()
+
+	pull.cancel()
+	<-pull.done
+}
+
+// run pulls pages of the paginator from the cursor in a goroutine and passes every item to deliver.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+// When pulling stops, its error is stored, the data channel is closed by closeData and pulling is marked as stopped.
+func (pull *pull) run(paginator *Paginator, cursor interface{}, deliver func(ctx context.Context, item interface{}) bool, closeData func()) {
+	pull.cursor = cursor
+
+	go func() {
+		cursor, err := pull.load(cursor)
+		if err == nil {
+			pull.setCursor(cursor)
+
+			err = pull.paginate(pull.ctx, paginator, deliver)
+
+			saveErr := pull.save(pull.getCursor())
+			if err == nil {
+				err = saveErr
+			}
+		}
+
+		pull.mu.Lock()
+		if !pull.closed {
+			pull.err = err
+		}
+		pull.mu.Unlock()
+
+		closeData()
+		pull.cancel()
+		close(pull.done)
+	}()
+}
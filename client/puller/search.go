@@ -0,0 +1,254 @@
+package puller
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// searchFilter returns filter of the type, e.g. client.TypeSearchMessagesFilterPhoto; empty filter if the type is empty.
+func searchFilter(filterType string) (client.SearchMessagesFilter, error) {
+	if filterType == "" {
+		filterType = client.TypeSearchMessagesFilterEmpty
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"@type": filterType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return client.UnmarshalSearchMessagesFilter(data)
+}
+
+// ChatSearchCursor contains position of a chat messages search.
+type ChatSearchCursor struct {
+	ChatID int64  `json:"chat_id"`
+	Query  string `json:"query"`
+	// Only messages sent by the user are returned; 0 for any sender
+	SenderUserID int32 `json:"sender_user_id"`
+	// Type of SearchMessagesFilter, e.g. client.TypeSearchMessagesFilterPhoto; empty for no filter
+	Filter string `json:"filter,omitempty"`
+	// Identifier of the last delivered message; 0 to start from the newest message
+	FromMessageID int64 `json:"from_message_id"`
+}
+
+// ChatSearchPuller pulls messages found in a chat.
+type ChatSearchPuller struct {
+	*messageStream
+}
+
+// Cursor returns position after the last delivered message.
+func (chatSearchPuller *ChatSearchPuller) Cursor() ChatSearchCursor {
+	return chatSearchPuller.getCursor().(ChatSearchCursor)
+}
+
+// SearchChatMessages pulls messages of the chat matching the cursor's query, sender and filter
+// from the cursor to the oldest message until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func SearchChatMessages(ctx context.Context, tdlibClient *client.Client, cursor ChatSearchCursor, options ...Option) *ChatSearchPuller {
+	chatSearchPuller := &ChatSearchPuller{
+		messageStream: newMessageStream(ctx, options),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			chatSearchCursor := cursor.(ChatSearchCursor)
+
+			filter, err := searchFilter(chatSearchCursor.Filter)
+			if err != nil {
+				return nil, err
+			}
+
+			messages, err := tdlibClient.SearchChatMessages(&client.SearchChatMessagesRequest{
+				ChatID:        chatSearchCursor.ChatID,
+				Query:         chatSearchCursor.Query,
+				SenderUserID:  chatSearchCursor.SenderUserID,
+				FromMessageID: chatSearchCursor.FromMessageID,
+				Limit:         limit,
+				Filter:        filter,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return &Page{
+				Items: messageItems(messages.Messages),
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			chatSearchCursor := cursor.(ChatSearchCursor)
+			chatSearchCursor.FromMessageID = page.Items[index].(*client.Message).ID
+
+			return chatSearchCursor
+		},
+	}
+
+	chatSearchPuller.run(paginator, cursor, chatSearchPuller.send, chatSearchPuller.close)
+
+	return chatSearchPuller
+}
+
+// SearchCursor contains position of a global messages search.
+type SearchCursor struct {
+	Query string `json:"query"`
+	// Date of the last delivered message; 0 to start from the newest message
+	OffsetDate int32 `json:"offset_date"`
+	// Chat identifier of the last delivered message
+	OffsetChatID int64 `json:"offset_chat_id"`
+	// Identifier of the last delivered message
+	OffsetMessageID int64 `json:"offset_message_id"`
+}
+
+// SearchPuller pulls messages found in all chats.
+type SearchPuller struct {
+	*messageStream
+}
+
+// Cursor returns position after the last delivered message.
+func (searchPuller *SearchPuller) Cursor() SearchCursor {
+	return searchPuller.getCursor().(SearchCursor)
+}
+
+// SearchMessages pulls messages of all chats matching the cursor's query from the newest to the oldest until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func SearchMessages(ctx context.Context, tdlibClient *client.Client, cursor SearchCursor, options ...Option) *SearchPuller {
+	searchPuller := &SearchPuller{
+		messageStream: newMessageStream(ctx, options),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			searchCursor := cursor.(SearchCursor)
+
+			messages, err := tdlibClient.SearchMessages(&client.SearchMessagesRequest{
+				Query:           searchCursor.Query,
+				OffsetDate:      searchCursor.OffsetDate,
+				OffsetChatID:    searchCursor.OffsetChatID,
+				OffsetMessageID: searchCursor.OffsetMessageID,
+				Limit:           limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return &Page{
+				Items: messageItems(messages.Messages),
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			message := page.Items[index].(*client.Message)
+
+			searchCursor := cursor.(SearchCursor)
+			searchCursor.OffsetDate = message.Date
+			searchCursor.OffsetChatID = message.ChatID
+			searchCursor.OffsetMessageID = message.ID
+
+			return searchCursor
+		},
+	}
+
+	searchPuller.run(paginator, cursor, searchPuller.send, searchPuller.close)
+
+	return searchPuller
+}
+
+// SecretSearchCursor contains position of a secret chats messages search.
+type SecretSearchCursor struct {
+	// Identifier of the secret chat; 0 to search in all secret chats
+	ChatID int64  `json:"chat_id"`
+	Query  string `json:"query"`
+	// Type of SearchMessagesFilter, e.g. client.TypeSearchMessagesFilterPhoto; empty for no filter
+	Filter string `json:"filter,omitempty"`
+	// Search identifier of the current page; 0 for the first page
+	FromSearchID int64 `json:"from_search_id"`
+	// Number of delivered messages of the current page
+	Delivered int `json:"delivered"`
+	// True, if there are no more pages
+	IsCompleted bool `json:"is_completed"`
+}
+
+// SecretSearchPuller pulls messages found in secret chats.
+type SecretSearchPuller struct {
+	*messageStream
+}
+
+// Cursor returns position after the last delivered message.
+func (secretSearchPuller *SecretSearchPuller) Cursor() SecretSearchCursor {
+	return secretSearchPuller.getCursor().(SecretSearchCursor)
+}
+
+// SearchSecretMessages pulls messages of secret chats matching the cursor's query and filter until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func SearchSecretMessages(ctx context.Context, tdlibClient *client.Client, cursor SecretSearchCursor, options ...Option) *SecretSearchPuller {
+	secretSearchPuller := &SecretSearchPuller{
+		messageStream: newMessageStream(ctx, options),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			secretSearchCursor := cursor.(SecretSearchCursor)
+			if secretSearchCursor.IsCompleted {
+				return &Page{
+					Last: true,
+				}, nil
+			}
+
+			filter, err := searchFilter(secretSearchCursor.Filter)
+			if err != nil {
+				return nil, err
+			}
+
+			foundMessages, err := tdlibClient.SearchSecretMessages(&client.SearchSecretMessagesRequest{
+				ChatID:       secretSearchCursor.ChatID,
+				Query:        secretSearchCursor.Query,
+				FromSearchID: client.Int64JSON(secretSearchCursor.FromSearchID),
+				Limit:        limit,
+				Filter:       filter,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			// messages delivered before the pull is resumed are skipped
+			items := make([]interface{}, len(foundMessages.Messages))
+			for i, message := range foundMessages.Messages {
+				if i >= secretSearchCursor.Delivered && message != nil {
+					items[i] = message
+				}
+			}
+
+			isCompleted := len(foundMessages.Messages) == 0 || foundMessages.NextFromSearchID == 0
+
+			return &Page{
+				Items: items,
+				Next: SecretSearchCursor{
+					ChatID:       secretSearchCursor.ChatID,
+					Query:        secretSearchCursor.Query,
+					Filter:       secretSearchCursor.Filter,
+					FromSearchID: int64(foundMessages.NextFromSearchID),
+					IsCompleted:  isCompleted,
+				},
+				Last: isCompleted,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			secretSearchCursor := cursor.(SecretSearchCursor)
+			secretSearchCursor.Delivered = index + 1
+
+			return secretSearchCursor
+		},
+	}
+
+	secretSearchPuller.run(paginator, cursor, secretSearchPuller.send, secretSearchPuller.close)
+
+	return secretSearchPuller
+}
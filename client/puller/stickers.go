@@ -0,0 +1,166 @@
+package puller
+
+import (
+	"context"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// StickerSetsKind is a kind of sticker sets to pull.
+type StickerSetsKind string
+
+const (
+	// StickerSetsInstalled pulls installed sticker sets
+	StickerSetsInstalled StickerSetsKind = "installed"
+	// StickerSetsArchived pulls archived sticker sets
+	StickerSetsArchived StickerSetsKind = "archived"
+	// StickerSetsTrending pulls trending sticker sets
+	StickerSetsTrending StickerSetsKind = "trending"
+)
+
+// StickerSetsCursor contains position of a sticker sets pull.
+type StickerSetsCursor struct {
+	// Kind of sticker sets; installed sticker sets if empty
+	Kind StickerSetsKind `json:"kind,omitempty"`
+	// True to pull mask sticker sets; ignored for trending sticker sets
+	IsMasks bool `json:"is_masks"`
+	// Identifier of the last delivered archived sticker set; 0 to start from the beginning
+	OffsetStickerSetID int64 `json:"offset_sticker_set_id"`
+	// Number of delivered installed or trending sticker sets
+	Offset int32 `json:"offset"`
+}
+
+// StickerSetPuller pulls sticker sets.
+type StickerSetPuller struct {
+	*pull
+	sets chan *client.StickerSetInfo
+}
+
+// Sets returns channel of pulled sticker sets. The channel is closed when pulling stops.
+func (stickerSetPuller *StickerSetPuller) Sets() <-chan *client.StickerSetInfo {
+	return stickerSetPuller.sets
+}
+
+// Next returns the next pulled sticker set. It returns false when pulling stops, see Err for the reason.
+func (stickerSetPuller *StickerSetPuller) Next() (*client.StickerSetInfo, bool) {
+	set, ok := <-stickerSetPuller.sets
+
+	return set, ok
+}
+
+// Cursor returns position after the last delivered sticker set.
+func (stickerSetPuller *StickerSetPuller) Cursor() StickerSetsCursor {
+	return stickerSetPuller.getCursor().(StickerSetsCursor)
+}
+
+// send delivers the sticker set. It returns false if the context is done before the sticker set is delivered.
+func (stickerSetPuller *StickerSetPuller) send(ctx context.Context, set interface{}) bool {
+	select {
+	case stickerSetPuller.sets <- set.(*client.StickerSetInfo):
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// InstalledStickerSets pulls installed sticker sets until the context is done.
+func InstalledStickerSets(ctx context.Context, tdlibClient *client.Client, isMasks bool, options ...Option) *StickerSetPuller {
+	return StickerSetsFrom(ctx, tdlibClient, StickerSetsCursor{Kind: StickerSetsInstalled, IsMasks: isMasks}, options...)
+}
+
+// ArchivedStickerSets pulls archived sticker sets until the context is done.
+func ArchivedStickerSets(ctx context.Context, tdlibClient *client.Client, isMasks bool, options ...Option) *StickerSetPuller {
+	return StickerSetsFrom(ctx, tdlibClient, StickerSetsCursor{Kind: StickerSetsArchived, IsMasks: isMasks}, options...)
+}
+
+// TrendingStickerSets pulls trending sticker sets until the context is done.
+func TrendingStickerSets(ctx context.Context, tdlibClient *client.Client, options ...Option) *StickerSetPuller {
+	return StickerSetsFrom(ctx, tdlibClient, StickerSetsCursor{Kind: StickerSetsTrending}, options...)
+}
+
+// StickerSetsFrom pulls sticker sets of the cursor's kind from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+func StickerSetsFrom(ctx context.Context, tdlibClient *client.Client, cursor StickerSetsCursor, options ...Option) *StickerSetPuller {
+	stickerSetPuller := &StickerSetPuller{
+		pull: newPull(ctx, options),
+		sets: make(chan *client.StickerSetInfo),
+	}
+
+	var limit int32 = 100
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			stickerSetsCursor := cursor.(StickerSetsCursor)
+
+			var stickerSets *client.StickerSets
+			var err error
+
+			switch stickerSetsCursor.Kind {
+			case StickerSetsArchived:
+				stickerSets, err = tdlibClient.GetArchivedStickerSets(&client.GetArchivedStickerSetsRequest{
+					IsMasks:            stickerSetsCursor.IsMasks,
+					OffsetStickerSetID: client.Int64JSON(stickerSetsCursor.OffsetStickerSetID),
+					Limit:              limit,
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				return &Page{
+					Items: stickerSetItems(stickerSets.Sets),
+				}, nil
+
+			case StickerSetsTrending:
+				stickerSets, err = tdlibClient.GetTrendingStickerSets()
+
+			default:
+				stickerSets, err = tdlibClient.GetInstalledStickerSets(&client.GetInstalledStickerSetsRequest{
+					IsMasks: stickerSetsCursor.IsMasks,
+				})
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			// installed and trending sticker sets are returned at once
+			sets := stickerSets.Sets
+			if int(stickerSetsCursor.Offset) < len(sets) {
+				sets = sets[stickerSetsCursor.Offset:]
+			} else {
+				sets = nil
+			}
+
+			return &Page{
+				Items: stickerSetItems(sets),
+				Last:  true,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			stickerSetsCursor := cursor.(StickerSetsCursor)
+			if stickerSetsCursor.Kind == StickerSetsArchived {
+				stickerSetsCursor.OffsetStickerSetID = int64(page.Items[index].(*client.StickerSetInfo).ID)
+			} else {
+				stickerSetsCursor.Offset = page.Cursor.(StickerSetsCursor).Offset + int32(index) + 1
+			}
+
+			return stickerSetsCursor
+		},
+	}
+
+	stickerSetPuller.run(paginator, cursor, stickerSetPuller.send, func() {
+		close(stickerSetPuller.sets)
+	})
+
+	return stickerSetPuller
+}
+
+// stickerSetItems returns the sticker sets as page items.
+func stickerSetItems(sets []*client.StickerSetInfo) []interface{} {
+	items := make([]interface{}, len(sets))
+	for i, set := range sets {
+		items[i] = set
+	}
+
+	return items
+}
@@ -1,54 +1,269 @@
 package puller
 
 import (
+	"context"
+	"unicode/utf8"
+
 	"github.com/u-robot/go-tdlib/client"
 )
 
-// SupergroupMembers returns channels to listen chat members and errors.
-func SupergroupMembers(tdlibClient *client.Client, supergroupID int32) (chan *client.ChatMember, chan error) {
-	chatMemberChan := make(chan *client.ChatMember, 10)
-	errChan := make(chan error, 1)
+// MembersFilter is a kind of supergroup members to pull.
+type MembersFilter string
 
-	var filter client.SupergroupMembersFilter
-	var offset int32
-	var limit int32 = 200
+const (
+	// MembersRecent pulls recently active members
+	MembersRecent MembersFilter = client.TypeSupergroupMembersFilterRecent
+	// MembersAdministrators pulls the owner and administrators
+	MembersAdministrators MembersFilter = client.TypeSupergroupMembersFilterAdministrators
+	// MembersSearch pulls members matching the queries
+	MembersSearch MembersFilter = client.TypeSupergroupMembersFilterSearch
+	// MembersRestricted pulls restricted members matching the queries
+	MembersRestricted MembersFilter = client.TypeSupergroupMembersFilterRestricted
+	// MembersBanned pulls banned members matching the queries
+	MembersBanned MembersFilter = client.TypeSupergroupMembersFilterBanned
+	// MembersBots pulls bot members
+	MembersBots MembersFilter = client.TypeSupergroupMembersFilterBots
+)
+
+// membersOverlap is number of members requested again from the previous page
+// to avoid gaps when members leave the supergroup during a pull.
+const membersOverlap = 10
 
-	go supergroupMembers(tdlibClient, chatMemberChan, errChan, supergroupID, filter, offset, limit)
+// membersQueryLimit is Telegram's limit of members returned for a single filter and query.
+const membersQueryLimit = 10000
 
-	return chatMemberChan, errChan
+// maxRefinedQueryLength is the maximal length in characters of queries produced by refinement of capped queries.
+const maxRefinedQueryLength = 3
+
+// SearchCharacters are characters appended to a query which matches more members than Telegram returns for a single query.
+var SearchCharacters = "abcdefghijklmnopqrstuvwxyz" +
+	"0123456789" +
+	"абвгдеёжзийклмнопрстуфхцчшщъыьэюя" +
+	"ґєії" +
+	"αβγδεζηθικλμνξοπρστυφχψω"
+
+// DefaultSearchQueries are queries used to pull all members of a supergroup.
+// The empty query matches all members and is refined with SearchCharacters if there are more members than Telegram returns.
+var DefaultSearchQueries = []string{""}
+
+// MembersCursor contains position of a supergroup members pull.
+type MembersCursor struct {
+	SupergroupID int32 `json:"supergroup_id"`
+	// Kind of members; recent members if empty
+	Filter MembersFilter `json:"filter,omitempty"`
+	// Queries of search, restricted and banned filters which are pulled one after another; refinements of a capped query are inserted after it
+	Queries []string `json:"queries,omitempty"`
+	// Index of the current query
+	QueryIndex int `json:"query_index"`
+	// Number of members of the current query which are already pulled
+	Offset int32 `json:"offset"`
 }
 
-func supergroupMembers(tdlibClient *client.Client, chatMemberChan chan *client.ChatMember, errChan chan error, supergroupID int32, filter client.SupergroupMembersFilter, offset int32, limit int32) {
-	defer func() {
-		close(chatMemberChan)
-		close(errChan)
-	}()
+// filter returns TDLib filter of the current query.
+func (cursor MembersCursor) filter() client.SupergroupMembersFilter {
+	var query string
+	if cursor.QueryIndex < len(cursor.Queries) {
+		query = cursor.Queries[cursor.QueryIndex]
+	}
 
-	var page int32
+	switch cursor.Filter {
+	case MembersAdministrators:
+		return &client.SupergroupMembersFilterAdministrators{}
+	case MembersSearch:
+		return &client.SupergroupMembersFilterSearch{Query: query}
+	case MembersRestricted:
+		return &client.SupergroupMembersFilterRestricted{Query: query}
+	case MembersBanned:
+		return &client.SupergroupMembersFilterBanned{Query: query}
+	case MembersBots:
+		return &client.SupergroupMembersFilterBots{}
+	}
 
-	for {
-		chatMembers, err := tdlibClient.GetSupergroupMembers(&client.GetSupergroupMembersRequest{
-			SupergroupID: supergroupID,
-			Filter:       filter,
-			Offset:       page*limit + offset,
-			Limit:        limit,
-		})
-		if err != nil {
-			errChan <- err
+	return &client.SupergroupMembersFilterRecent{}
+}
 
-			return
-		}
+// requestOffset returns offset of the page request which overlaps the previous page.
+func (cursor MembersCursor) requestOffset() int32 {
+	if cursor.Offset > membersOverlap {
+		return cursor.Offset - membersOverlap
+	}
 
-		if len(chatMembers.Members) == 0 {
-			errChan <- ErrEndOfPull
+	return 0
+}
 
-			break
-		}
+// hasQueries returns true if the filter of the cursor uses queries.
+func (cursor MembersCursor) hasQueries() bool {
+	return cursor.Filter == MembersSearch || cursor.Filter == MembersRestricted || cursor.Filter == MembersBanned
+}
 
-		for _, member := range chatMembers.Members {
-			chatMemberChan <- member
+// isCapped returns true if the current query matches the total count of members,
+// but Telegram returns only part of them and the query can be refined.
+func (cursor MembersCursor) isCapped(totalCount int32) bool {
+	if !cursor.hasQueries() || cursor.QueryIndex >= len(cursor.Queries) || totalCount < membersQueryLimit {
+		return false
+	}
+
+	return utf8.RuneCountInString(cursor.Queries[cursor.QueryIndex]) < maxRefinedQueryLength
+}
+
+// refine returns queries with the current query followed by the query with every one of SearchCharacters appended.
+// Queries which are already in the list are not added again.
+func (cursor MembersCursor) refine() []string {
+	queries := map[string]bool{}
+	for _, query := range cursor.Queries {
+		queries[query] = true
+	}
+
+	query := cursor.Queries[cursor.QueryIndex]
+
+	var refined []string
+	for _, character := range SearchCharacters {
+		if !queries[query+string(character)] {
+			refined = append(refined, query+string(character))
 		}
+	}
+
+	result := make([]string, 0, len(cursor.Queries)+len(refined))
+	result = append(result, cursor.Queries[:cursor.QueryIndex+1]...)
+	result = append(result, refined...)
+	result = append(result, cursor.Queries[cursor.QueryIndex+1:]...)
+
+	return result
+}
+
+// ChatMemberPuller pulls chat members. Every member is delivered once per pull.
+type ChatMemberPuller struct {
+	*pull
+	members chan *client.ChatMember
+}
+
+// Members returns channel of pulled chat members. The channel is closed when pulling stops.
+func (chatMemberPuller *ChatMemberPuller) Members() <-chan *client.ChatMember {
+	return chatMemberPuller.members
+}
 
-		page++
+// Next returns the next pulled chat member. It returns false when pulling stops, see Err for the reason.
+func (chatMemberPuller *ChatMemberPuller) Next() (*client.ChatMember, bool) {
+	member, ok := <-chatMemberPuller.members
+
+	return member, ok
+}
+
+// Cursor returns position after the last delivered chat member.
+func (chatMemberPuller *ChatMemberPuller) Cursor() MembersCursor {
+	return chatMemberPuller.getCursor().(MembersCursor)
+}
+
+// send delivers the chat member. It returns false if the context is done before the chat member is delivered.
+func (chatMemberPuller *ChatMemberPuller) send(ctx context.Context, member interface{}) bool {
+	select {
+	case chatMemberPuller.members <- member.(*client.ChatMember):
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// SupergroupMembers pulls recent members of the supergroup until the context is done.
+func SupergroupMembers(ctx context.Context, tdlibClient *client.Client, supergroupID int32, options ...Option) *ChatMemberPuller {
+	return SupergroupMembersFrom(ctx, tdlibClient, MembersCursor{SupergroupID: supergroupID}, options...)
+}
+
+// SupergroupMembersFiltered pulls members of the supergroup of the kind until the context is done.
+// Search, restricted and banned members are pulled for every query one after another; empty query matches all members.
+// A query matching more members than Telegram returns is followed by queries with every one of SearchCharacters appended.
+func SupergroupMembersFiltered(ctx context.Context, tdlibClient *client.Client, supergroupID int32, filter MembersFilter, queries []string, options ...Option) *ChatMemberPuller {
+	return SupergroupMembersFrom(ctx, tdlibClient, MembersCursor{
+		SupergroupID: supergroupID,
+		Filter:       filter,
+		Queries:      queries,
+	}, options...)
+}
+
+// AllSupergroupMembers pulls members of the supergroup by searching with DefaultSearchQueries
+// to get more members than Telegram returns for a single filter.
+// Enumeration is best-effort: members whose names and usernames don't start with SearchCharacters
+// can be missed in supergroups with more members than Telegram returns for a single query.
+func AllSupergroupMembers(ctx context.Context, tdlibClient *client.Client, supergroupID int32, options ...Option) *ChatMemberPuller {
+	return SupergroupMembersFiltered(ctx, tdlibClient, supergroupID, MembersSearch, DefaultSearchQueries, options...)
+}
+
+// SupergroupMembersFrom pulls members of the supergroup from the cursor until the context is done.
+// The cursor stored in the checkpoint store takes precedence over the passed one.
+// Members are deduplicated by user identifier within the pull, but not across resumed pulls.
+func SupergroupMembersFrom(ctx context.Context, tdlibClient *client.Client, cursor MembersCursor, options ...Option) *ChatMemberPuller {
+	chatMemberPuller := &ChatMemberPuller{
+		pull:    newPull(ctx, options),
+		members: make(chan *client.ChatMember),
 	}
+
+	var limit int32 = 200
+	seen := map[int32]bool{}
+
+	paginator := &Paginator{
+		Fetch: func(ctx context.Context, cursor interface{}) (*Page, error) {
+			membersCursor := cursor.(MembersCursor)
+			offset := membersCursor.requestOffset()
+
+			chatMembers, err := tdlibClient.GetSupergroupMembers(&client.GetSupergroupMembersRequest{
+				SupergroupID: membersCursor.SupergroupID,
+				Filter:       membersCursor.filter(),
+				Offset:       offset,
+				Limit:        limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			// the query is exhausted if the page doesn't move the offset forward
+			if offset+int32(len(chatMembers.Members)) <= membersCursor.Offset || len(chatMembers.Members) == 0 {
+				if membersCursor.isCapped(chatMembers.TotalCount) {
+					membersCursor.Queries = membersCursor.refine()
+				}
+
+				if !membersCursor.hasQueries() || membersCursor.QueryIndex+1 >= len(membersCursor.Queries) {
+					return &Page{
+						Last: true,
+					}, nil
+				}
+
+				membersCursor.QueryIndex++
+				membersCursor.Offset = 0
+
+				return &Page{
+					Next: membersCursor,
+				}, nil
+			}
+
+			// members delivered earlier are not delivered again, but the offset is advanced over them
+			items := make([]interface{}, len(chatMembers.Members))
+			for i, member := range chatMembers.Members {
+				if !seen[member.UserID] {
+					seen[member.UserID] = true
+					items[i] = member
+				}
+			}
+
+			return &Page{
+				Items: items,
+			}, nil
+		},
+		Advance: func(cursor interface{}, page *Page, index int) interface{} {
+			membersCursor := cursor.(MembersCursor)
+
+			offset := page.Cursor.(MembersCursor).requestOffset() + int32(index) + 1
+			if offset > membersCursor.Offset {
+				membersCursor.Offset = offset
+			}
+
+			return membersCursor
+		},
+	}
+
+	chatMemberPuller.run(paginator, cursor, chatMemberPuller.send, func() {
+		close(chatMemberPuller.members)
+	})
+
+	return chatMemberPuller
 }
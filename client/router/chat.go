@@ -0,0 +1,137 @@
+package router
+
+import (
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// ChatID returns identifier of the chat the update belongs to.
+// It returns false if the update is not related to any chat.
+func ChatID(update client.Update) (int64, bool) {
+	switch u := update.(type) {
+	case *client.UpdateNewMessage:
+		return messageChatID(u.Message)
+	case *client.UpdateMessageSendSucceeded:
+		return messageChatID(u.Message)
+	case *client.UpdateMessageSendFailed:
+		return messageChatID(u.Message)
+	case *client.UpdateNewChat:
+		if u.Chat == nil {
+			return 0, false
+		}
+		return u.Chat.ID, true
+	case *client.UpdateMessageSendAcknowledged:
+		return u.ChatID, true
+	case *client.UpdateMessageContent:
+		return u.ChatID, true
+	case *client.UpdateMessageEdited:
+		return u.ChatID, true
+	case *client.UpdateMessageViews:
+		return u.ChatID, true
+	case *client.UpdateMessageContentOpened:
+		return u.ChatID, true
+	case *client.UpdateMessageMentionRead:
+		return u.ChatID, true
+	case *client.UpdateChatTitle:
+		return u.ChatID, true
+	case *client.UpdateChatPhoto:
+		return u.ChatID, true
+	case *client.UpdateChatLastMessage:
+		return u.ChatID, true
+	case *client.UpdateChatOrder:
+		return u.ChatID, true
+	case *client.UpdateChatIsPinned:
+		return u.ChatID, true
+	case *client.UpdateChatIsMarkedAsUnread:
+		return u.ChatID, true
+	case *client.UpdateChatIsSponsored:
+		return u.ChatID, true
+	case *client.UpdateChatDefaultDisableNotification:
+		return u.ChatID, true
+	case *client.UpdateChatReadInbox:
+		return u.ChatID, true
+	case *client.UpdateChatReadOutbox:
+		return u.ChatID, true
+	case *client.UpdateChatUnreadMentionCount:
+		return u.ChatID, true
+	case *client.UpdateChatNotificationSettings:
+		return u.ChatID, true
+	case *client.UpdateChatReplyMarkup:
+		return u.ChatID, true
+	case *client.UpdateChatDraftMessage:
+		return u.ChatID, true
+	case *client.UpdateDeleteMessages:
+		return u.ChatID, true
+	case *client.UpdateUserChatAction:
+		return u.ChatID, true
+	case *client.UpdateNewCallbackQuery:
+		return u.ChatID, true
+	}
+
+	return 0, false
+}
+
+func messageChatID(message *client.Message) (int64, bool) {
+	if message == nil {
+		return 0, false
+	}
+
+	return message.ChatID, true
+}
+
+// Type returns predicate matching updates of any of specified types.
+func Type(updateTypes ...string) Predicate {
+	return func(update client.Update) bool {
+		for _, updateType := range updateTypes {
+			if update.UpdateType() == updateType {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Chat returns predicate matching updates belonging to any of specified chats.
+func Chat(chatIDs ...int64) Predicate {
+	return func(update client.Update) bool {
+		chatID, ok := ChatID(update)
+		if !ok {
+			return false
+		}
+
+		for _, id := range chatIDs {
+			if id == chatID {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Incoming returns predicate matching new messages which are not outgoing.
+func Incoming() Predicate {
+	return func(update client.Update) bool {
+		newMessage, ok := update.(*client.UpdateNewMessage)
+
+		return ok && newMessage.Message != nil && !newMessage.Message.IsOutgoing
+	}
+}
+
+// Content returns predicate matching new messages with content of any of specified types (e.g. client.TypeMessageText).
+func Content(contentTypes ...string) Predicate {
+	return func(update client.Update) bool {
+		newMessage, ok := update.(*client.UpdateNewMessage)
+		if !ok || newMessage.Message == nil || newMessage.Message.Content == nil {
+			return false
+		}
+
+		for _, contentType := range contentTypes {
+			if newMessage.Message.Content.MessageContentType() == contentType {
+				return true
+			}
+		}
+
+		return false
+	}
+}
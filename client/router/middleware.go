@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Middleware is a function type which wraps a handler with additional behaviour.
+type Middleware func(next Handler) Handler
+
+// PanicError is an error returned by a handler which panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns string describing the panic.
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", err.Value, err.Stack)
+}
+
+// Recover returns middleware which converts handler panics into PanicError.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, update client.Update) (err error) {
+			defer func() {
+				if value := recover(); value != nil {
+					err = &PanicError{
+						Value: value,
+						Stack: debug.Stack(),
+					}
+				}
+			}()
+
+			return next(ctx, update)
+		}
+	}
+}
+
+// Logger returns middleware which logs every handled update with its duration and error.
+// Standard logger is used if logger is nil.
+func Logger(logger *log.Logger) Middleware {
+	printf := log.Printf
+	if logger != nil {
+		printf = logger.Printf
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, update client.Update) error {
+			start := time.Now()
+
+			err := next(ctx, update)
+			if err != nil {
+				printf("%s handled in %s with error: %s\n", update.UpdateType(), time.Since(start), err)
+			} else {
+				printf("%s handled in %s\n", update.UpdateType(), time.Since(start))
+			}
+
+			return err
+		}
+	}
+}
+
+// Filter returns middleware which skips updates not matching all predicates.
+func Filter(predicates ...Predicate) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, update client.Update) error {
+			for _, predicate := range predicates {
+				if !predicate(update) {
+					return nil
+				}
+			}
+
+			return next(ctx, update)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// queue is an unbounded FIFO queue of updates handled by a single worker. Pushing never blocks.
+type queue struct {
+	mu      sync.Mutex
+	updates []client.Update
+	closed  bool
+	signal  chan struct{}
+}
+
+func newQueue(capacity int) *queue {
+	return &queue{
+		updates: make([]client.Update, 0, capacity),
+		signal:  make(chan struct{}, 1),
+	}
+}
+
+// push appends the update unless the queue already contains limit updates; zero limit means no limit.
+// It returns false if the update is not appended.
+func (queue *queue) push(update client.Update, limit int) bool {
+	queue.mu.Lock()
+	if limit > 0 && len(queue.updates) >= limit {
+		queue.mu.Unlock()
+
+		return false
+	}
+	queue.updates = append(queue.updates, update)
+	queue.mu.Unlock()
+
+	queue.notify()
+
+	return true
+}
+
+// pop removes and returns the first update waiting for it if the queue is empty.
+// It returns false when the queue is closed and all its updates are popped.
+func (queue *queue) pop() (client.Update, bool) {
+	for {
+		queue.mu.Lock()
+		if len(queue.updates) > 0 {
+			update := queue.updates[0]
+			queue.updates[0] = nil
+			queue.updates = queue.updates[1:]
+			queue.mu.Unlock()
+
+			return update, true
+		}
+		closed := queue.closed
+		queue.mu.Unlock()
+
+		if closed {
+			return nil, false
+		}
+
+		<-queue.signal
+	}
+}
+
+// close stops the queue after the pushed updates are popped.
+func (queue *queue) close() {
+	queue.mu.Lock()
+	queue.closed = true
+	queue.mu.Unlock()
+
+	queue.notify()
+}
+
+func (queue *queue) notify() {
+	select {
+	case queue.signal <- struct{}{}:
+	default:
+	}
+}
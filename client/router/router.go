@@ -0,0 +1,264 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// ErrQueueOverflow is passed to the error handler for updates dropped because the worker's queue is full.
+var ErrQueueOverflow = errors.New("queue overflow")
+
+// OverflowPolicy defines what the router does with an update when the worker's queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBuffer grows the queue beyond its size, so no update is lost
+	OverflowBuffer OverflowPolicy = iota
+	// OverflowDrop drops the update reporting ErrQueueOverflow to the error handler
+	OverflowDrop
+)
+
+// Handler is a function type which handles a single update.
+type Handler func(ctx context.Context, update client.Update) error
+
+// Predicate is a function type which reports whether an update should be handled by a route.
+type Predicate func(update client.Update) bool
+
+// ErrorHandler is a function type which is called when a handler returns an error or panics.
+type ErrorHandler func(update client.Update, err error)
+
+type route struct {
+	updateType string
+	predicates []Predicate
+	handler    Handler
+}
+
+func (r *route) match(update client.Update) bool {
+	if r.updateType != "" && r.updateType != update.UpdateType() {
+		return false
+	}
+
+	for _, predicate := range r.predicates {
+		if !predicate(update) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Router dispatches updates received from a client to registered handlers.
+// Updates belonging to the same chat are always handled sequentially in order of arrival,
+// updates of different chats are handled concurrently by a bounded pool of workers.
+type Router struct {
+	tdlibClient  *client.Client
+	mu           sync.RWMutex
+	routes       []*route
+	middlewares  []Middleware
+	workers      int
+	queueSize    int
+	overflow     OverflowPolicy
+	errorHandler ErrorHandler
+}
+
+// Option is a function type which adjusts router's configuration.
+type Option func(*Router)
+
+// WithWorkers configures the router to use specified number of workers.
+func WithWorkers(workers int) Option {
+	return func(router *Router) {
+		if workers > 0 {
+			router.workers = workers
+		}
+	}
+}
+
+// WithQueueSize configures the router to use specified size of each worker's queue.
+// Queues grow beyond the size unless OverflowDrop policy is configured.
+func WithQueueSize(queueSize int) Option {
+	return func(router *Router) {
+		if queueSize > 0 {
+			router.queueSize = queueSize
+		}
+	}
+}
+
+// WithOverflowPolicy configures the router to use specified policy when a worker's queue is full.
+// Updates are buffered by default. Receiving of updates is never blocked by slow handlers.
+func WithOverflowPolicy(overflow OverflowPolicy) Option {
+	return func(router *Router) {
+		router.overflow = overflow
+	}
+}
+
+// WithErrorHandler configures the router to use specified error handler.
+func WithErrorHandler(errorHandler ErrorHandler) Option {
+	return func(router *Router) {
+		router.errorHandler = errorHandler
+	}
+}
+
+// New creates new router for the client.
+func New(tdlibClient *client.Client, options ...Option) *Router {
+	router := &Router{
+		tdlibClient: tdlibClient,
+		workers:     8,
+		queueSize:   128,
+		errorHandler: func(update client.Update, err error) {
+			log.Printf("Error occurred during handling %s: %s\n", update.UpdateType(), err)
+		},
+	}
+
+	for _, option := range options {
+		option(router)
+	}
+
+	return router
+}
+
+// Client returns the client which updates are dispatched by the router.
+func (router *Router) Client() *client.Client {
+	return router.tdlibClient
+}
+
+// Use appends middlewares applied to every handler of the router.
+// The first middleware is the outermost one.
+func (router *Router) Use(middlewares ...Middleware) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+// Handle registers handler for updates of specified type (e.g. client.TypeUpdateNewMessage) matching all predicates.
+// Routes are checked in order of registration and only the first matching route handles an update.
+func (router *Router) Handle(updateType string, handler Handler, predicates ...Predicate) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	router.routes = append(router.routes, &route{
+		updateType: updateType,
+		predicates: predicates,
+		handler:    handler,
+	})
+}
+
+// HandleAny registers handler for updates of any type matching all predicates.
+func (router *Router) HandleAny(handler Handler, predicates ...Predicate) {
+	router.Handle("", handler, predicates...)
+}
+
+// Run listens client's updates and dispatches them until the context is done.
+func (router *Router) Run(ctx context.Context) error {
+	listener := router.tdlibClient.GetListener()
+	defer listener.Close()
+
+	return router.Serve(ctx, listener.Updates)
+}
+
+// Serve dispatches updates received from the channel until the context is done or the channel is closed.
+// It waits for all running handlers to finish before returning.
+func (router *Router) Serve(ctx context.Context, updates <-chan client.Type) error {
+	queues := make([]*queue, router.workers)
+
+	limit := 0
+	if router.overflow == OverflowDrop {
+		limit = router.queueSize
+	}
+
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = newQueue(router.queueSize)
+
+		wg.Add(1)
+		go func(queue *queue) {
+			defer wg.Done()
+
+			for {
+				update, ok := queue.pop()
+				if !ok {
+					return
+				}
+
+				router.Dispatch(ctx, update)
+			}
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			queue.close()
+		}
+		wg.Wait()
+	}()
+
+	var next int
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case typ, ok := <-updates:
+			if !ok {
+				return nil
+			}
+
+			update, ok := typ.(client.Update)
+			if !ok {
+				continue
+			}
+
+			var queue *queue
+
+			chatID, ok := ChatID(update)
+			if ok {
+				queue = queues[uint64(chatID)%uint64(len(queues))]
+			} else {
+				queue = queues[next]
+				next = (next + 1) % len(queues)
+			}
+
+			if !queue.push(update, limit) && router.errorHandler != nil {
+				router.errorHandler(update, ErrQueueOverflow)
+			}
+		}
+	}
+}
+
+// Dispatch synchronously handles the update by the first matching route.
+// It returns false if there is no route for the update.
+func (router *Router) Dispatch(ctx context.Context, update client.Update) bool {
+	router.mu.RLock()
+	var handler Handler
+	for _, route := range router.routes {
+		if route.match(update) {
+			handler = route.handler
+			break
+		}
+	}
+	middlewares := router.middlewares
+	router.mu.RUnlock()
+
+	if handler == nil {
+		return false
+	}
+
+	// Panics are recovered both inside middlewares, so they are able to observe them as errors,
+	// and outside, so panicking middlewares do not break the worker.
+	handler = Recover()(handler)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	err := Recover()(handler)(ctx, update)
+	if err != nil && router.errorHandler != nil {
+		router.errorHandler(update, err)
+	}
+
+	return true
+}
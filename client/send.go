@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SendMessageError is an error returned when TDLib fails to send a message to the server.
+type SendMessageError struct {
+	// Message which failed to send
+	Message *Message
+	Err     *Error
+}
+
+// Error returns string describing reason of message sending fail.
+func (sendMessageError SendMessageError) Error() string {
+	return fmt.Sprintf("message sending failed: %d %s", sendMessageError.Err.Code, sendMessageError.Err.Message)
+}
+
+// SendMessageAndWait sends a message and waits until it is sent to the server.
+// It returns the message with its final server-side identifier or SendMessageError.
+func (client *Client) SendMessageAndWait(ctx context.Context, request *SendMessageRequest) (*Message, error) {
+	listener := client.GetListener()
+	defer listener.Close()
+
+	message, err := client.SendMessage(request)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := waitMessagesSent(ctx, listener, []*Message{message})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages[0], nil
+}
+
+// SendMessageAlbumAndWait sends messages grouped together into an album and waits until all of them are sent to the server.
+// If some messages fail to send, the successfully sent messages are returned together with the first SendMessageError.
+func (client *Client) SendMessageAlbumAndWait(ctx context.Context, request *SendMessageAlbumRequest) ([]*Message, error) {
+	listener := client.GetListener()
+	defer listener.Close()
+
+	messages, err := client.SendMessageAlbum(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return waitMessagesSent(ctx, listener, messages.Messages)
+}
+
+// ForwardMessagesAndWait forwards messages and waits until all of them are sent to the server.
+// Messages which can't be forwarded are returned as nil, in the same way as ForwardMessages does.
+func (client *Client) ForwardMessagesAndWait(ctx context.Context, request *ForwardMessagesRequest) ([]*Message, error) {
+	listener := client.GetListener()
+	defer listener.Close()
+
+	messages, err := client.ForwardMessages(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return waitMessagesSent(ctx, listener, messages.Messages)
+}
+
+// waitMessagesSent waits for updateMessageSendSucceeded or updateMessageSendFailed of every pending message
+// and replaces temporary messages with the final ones.
+func waitMessagesSent(ctx context.Context, listener *Listener, messages []*Message) ([]*Message, error) {
+	result := make([]*Message, len(messages))
+	copy(result, messages)
+
+	type key struct {
+		chatID    int64
+		messageID int64
+	}
+
+	pending := map[key]int{}
+	for i, message := range messages {
+		if message != nil && message.SendingState != nil {
+			pending[key{message.ChatID, message.ID}] = i
+		}
+	}
+
+	var sendErr error
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+
+		case update, ok := <-listener.Updates:
+			if !ok {
+				return result, errors.New("listener is closed")
+			}
+
+			switch u := update.(type) {
+			case *UpdateMessageSendSucceeded:
+				i, ok := pending[key{u.Message.ChatID, u.OldMessageID}]
+				if !ok {
+					continue
+				}
+				delete(pending, key{u.Message.ChatID, u.OldMessageID})
+
+				result[i] = u.Message
+
+			case *UpdateMessageSendFailed:
+				i, ok := pending[key{u.Message.ChatID, u.OldMessageID}]
+				if !ok {
+					continue
+				}
+				delete(pending, key{u.Message.ChatID, u.OldMessageID})
+
+				result[i] = u.Message
+				if sendErr == nil {
+					sendErr = SendMessageError{
+						Message: u.Message,
+						Err: &Error{
+							Code:    u.ErrorCode,
+							Message: u.ErrorMessage,
+						},
+					}
+				}
+			}
+		}
+	}
+
+	return result, sendErr
+}
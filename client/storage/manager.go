@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Manager periodically samples storage usage statistics and deletes files according to policies.
+type Manager struct {
+	tdlibClient *client.Client
+	mu          sync.Mutex
+	interval    time.Duration
+	chatLimit   int32
+	policies    []*Policy
+	reporter    func(*Report)
+	last        *client.StorageStatisticsFast
+}
+
+// Option is a function type which adjusts manager's configuration.
+type Option func(*Manager)
+
+// WithInterval configures the manager to check storage usage with specified interval.
+func WithInterval(interval time.Duration) Option {
+	return func(manager *Manager) {
+		if interval > 0 {
+			manager.interval = interval
+		}
+	}
+}
+
+// WithChatLimit configures the manager to return separate statistics for specified number of chats with the largest storage usage.
+func WithChatLimit(chatLimit int32) Option {
+	return func(manager *Manager) {
+		manager.chatLimit = chatLimit
+	}
+}
+
+// WithPolicies configures the manager to apply the policies in the given order.
+func WithPolicies(policies ...*Policy) Option {
+	return func(manager *Manager) {
+		manager.policies = append(manager.policies, policies...)
+	}
+}
+
+// WithReporter configures the manager to call the function with report of every applied policy.
+// By default reports are logged.
+func WithReporter(reporter func(*Report)) Option {
+	return func(manager *Manager) {
+		manager.reporter = reporter
+	}
+}
+
+// New creates new storage manager.
+func New(tdlibClient *client.Client, options ...Option) *Manager {
+	manager := &Manager{
+		tdlibClient: tdlibClient,
+		interval:    time.Hour,
+		chatLimit:   10,
+		reporter:    logReport,
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager
+}
+
+// AddPolicy adds the policy applied after the existing ones.
+func (manager *Manager) AddPolicy(policy *Policy) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	manager.policies = append(manager.policies, policy)
+}
+
+// Run checks storage usage with the configured interval until the context is done.
+func (manager *Manager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(manager.interval)
+	defer ticker.Stop()
+
+	for {
+		manager.Check()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check samples storage usage and applies all policies. Reports of the applied policies are returned.
+func (manager *Manager) Check() []*Report {
+	manager.mu.Lock()
+	policies := make([]*Policy, len(manager.policies))
+	copy(policies, manager.policies)
+	manager.mu.Unlock()
+
+	var reports []*Report
+	for _, policy := range policies {
+		report := manager.Apply(policy)
+		if report != nil {
+			reports = append(reports, report)
+		}
+	}
+
+	if len(policies) == 0 {
+		_, err := manager.FastStatistics()
+		if err != nil {
+			log.Printf("storage statistics error: %s\n", err)
+		}
+	}
+
+	return reports
+}
+
+// Apply applies the policy. It returns nil if storage usage is below the policy's threshold.
+func (manager *Manager) Apply(policy *Policy) *Report {
+	report := &Report{
+		Policy: policy,
+	}
+
+	report.Before, report.Err = manager.FastStatistics()
+	if report.Err != nil {
+		manager.reporter(report)
+
+		return report
+	}
+
+	if report.Before.FilesSize < policy.Threshold {
+		return nil
+	}
+
+	request := policy.request()
+	request.ChatLimit = manager.chatLimit
+
+	// OptimizeStorage returns the remaining storage usage, so freed storage is measured by samples around it
+	report.Remaining, report.Err = manager.tdlibClient.OptimizeStorage(request)
+	if report.Err == nil {
+		report.After, report.Err = manager.FastStatistics()
+	}
+
+	manager.reporter(report)
+
+	return report
+}
+
+// Statistics returns detailed storage usage statistics.
+func (manager *Manager) Statistics() (*client.StorageStatistics, error) {
+	return manager.tdlibClient.GetStorageStatistics(&client.GetStorageStatisticsRequest{
+		ChatLimit: manager.chatLimit,
+	})
+}
+
+// FastStatistics returns approximate storage usage statistics and remembers them as the last sample.
+func (manager *Manager) FastStatistics() (*client.StorageStatisticsFast, error) {
+	statistics, err := manager.tdlibClient.GetStorageStatisticsFast()
+	if err != nil {
+		return nil, err
+	}
+
+	manager.mu.Lock()
+	manager.last = statistics
+	manager.mu.Unlock()
+
+	return statistics, nil
+}
+
+// LastStatistics returns the last sampled approximate storage usage statistics; nil if storage usage is not sampled yet.
+func (manager *Manager) LastStatistics() *client.StorageStatisticsFast {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	return manager.last
+}
+
+func logReport(report *Report) {
+	if report.Err != nil {
+		log.Printf("storage policy %q error: %s\n", report.Policy.Name, report.Err)
+
+		return
+	}
+
+	log.Printf("storage policy %q freed %d bytes in %d files\n", report.Policy.Name, report.FreedSize(), report.FreedCount())
+}
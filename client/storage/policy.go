@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"math"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Policy declares which files are deleted by OptimizeStorage.
+// Zero limits mean no limit.
+type Policy struct {
+	// Name of the policy used in reports
+	Name string
+	// Policy is applied only if approximate total size of files is at least the threshold
+	Threshold int64
+	// Limit on the total size of the considered files after deletion
+	MaxSize int64
+	// Limit on the total count of the considered files after deletion
+	MaxCount int32
+	// Files which were not accessed during the time are deleted
+	TTL time.Duration
+	// Files created during the delay can't be deleted; negative for TDLib's default delay
+	ImmunityDelay time.Duration
+	// If not empty, only files of the types are considered
+	FileTypes []client.FileType
+	// If not empty, only files from the chats are considered; 0 is for files not belonging to any chat
+	ChatIDs []int64
+	// Files from the chats are never deleted; 0 is for files not belonging to any chat
+	ExcludeChatIDs []int64
+}
+
+// request returns OptimizeStorage request applying the policy.
+func (policy *Policy) request() *client.OptimizeStorageRequest {
+	request := &client.OptimizeStorageRequest{
+		Size:           math.MaxInt64,
+		TTL:            math.MaxInt32,
+		Count:          math.MaxInt32,
+		ImmunityDelay:  -1,
+		FileTypes:      policy.FileTypes,
+		ChatIDs:        policy.ChatIDs,
+		ExcludeChatIDs: policy.ExcludeChatIDs,
+	}
+
+	if policy.MaxSize > 0 {
+		request.Size = policy.MaxSize
+	}
+
+	if policy.MaxCount > 0 {
+		request.Count = policy.MaxCount
+	}
+
+	if policy.TTL > 0 {
+		request.TTL = int32(policy.TTL / time.Second)
+	}
+
+	if policy.ImmunityDelay >= 0 {
+		request.ImmunityDelay = int32(policy.ImmunityDelay / time.Second)
+	}
+
+	return request
+}
+
+// Report contains result of applying a policy.
+type Report struct {
+	// Applied policy
+	Policy *Policy
+	// Approximate storage usage before applying the policy
+	Before *client.StorageStatisticsFast
+	// Approximate storage usage after applying the policy
+	After *client.StorageStatisticsFast
+	// Storage usage left after applying the policy as returned by TDLib
+	Remaining *client.StorageStatistics
+	// Error of applying the policy
+	Err error
+}
+
+// FreedSize returns approximate size of deleted files as the difference of storage usage before and after applying the policy.
+func (report *Report) FreedSize() int64 {
+	if report.Before == nil || report.After == nil || report.After.FilesSize > report.Before.FilesSize {
+		return 0
+	}
+
+	return report.Before.FilesSize - report.After.FilesSize
+}
+
+// FreedCount returns approximate number of deleted files as the difference of storage usage before and after applying the policy.
+func (report *Report) FreedCount() int32 {
+	if report.Before == nil || report.After == nil || report.After.FileCount > report.Before.FileCount {
+		return 0
+	}
+
+	return report.Before.FileCount - report.After.FileCount
+}
@@ -0,0 +1,331 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+var (
+	// ErrCancelled is error returned when an upload is cancelled.
+	ErrCancelled = errors.New("upload cancelled")
+	// ErrFailed is error returned when an upload is stopped by TDLib before completion.
+	ErrFailed = errors.New("upload failed")
+)
+
+type messageKey struct {
+	chatID    int64
+	messageID int64
+}
+
+// sentMessage is a result of sending a message received before the message is tracked.
+type sentMessage struct {
+	message *client.Message
+	err     error
+}
+
+// Manager starts file uploads and tracks progress of uploaded files and outgoing messages.
+// Manager must be running (see Run) or receive updates by HandleUpdate to track uploads.
+type Manager struct {
+	tdlibClient *client.Client
+	mu          sync.Mutex
+	uploads     map[int32]*Upload
+	messages    map[messageKey]*MessageUpload
+	// results of sending received while send requests are waiting for TDLib response
+	sent map[messageKey]sentMessage
+	// number of send requests waiting for TDLib response
+	sending int
+	window  time.Duration
+	meter   *meter
+}
+
+// Option is a function type which adjusts manager's configuration.
+type Option func(*Manager)
+
+// WithThroughputWindow configures the manager to measure throughput over specified time window.
+func WithThroughputWindow(window time.Duration) Option {
+	return func(manager *Manager) {
+		if window > 0 {
+			manager.window = window
+		}
+	}
+}
+
+// New creates new upload manager.
+func New(tdlibClient *client.Client, options ...Option) *Manager {
+	manager := &Manager{
+		tdlibClient: tdlibClient,
+		uploads:     map[int32]*Upload{},
+		messages:    map[messageKey]*MessageUpload{},
+		sent:        map[messageKey]sentMessage{},
+		window:      5 * time.Second,
+	}
+
+	for _, option := range options {
+		option(manager)
+	}
+
+	manager.meter = newMeter(manager.window)
+
+	return manager
+}
+
+// Run listens client's updates and tracks uploads until the context is done.
+func (manager *Manager) Run(ctx context.Context) error {
+	listener := manager.tdlibClient.GetListener()
+	defer listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case update, ok := <-listener.Updates:
+			if !ok {
+				return nil
+			}
+
+			if u, ok := update.(client.Update); ok {
+				manager.HandleUpdate(u)
+			}
+		}
+	}
+}
+
+// HandleUpdate tracks progress of uploads by updateFile and sending of messages
+// by updateMessageSendSucceeded and updateMessageSendFailed.
+func (manager *Manager) HandleUpdate(update client.Update) {
+	switch u := update.(type) {
+	case *client.UpdateFile:
+		if u.File != nil {
+			manager.update(u.File)
+		}
+
+	case *client.UpdateMessageSendSucceeded:
+		manager.messageSent(u.Message, u.OldMessageID, nil)
+
+	case *client.UpdateMessageSendFailed:
+		manager.messageSent(u.Message, u.OldMessageID, client.SendMessageError{
+			Message: u.Message,
+			Err: &client.Error{
+				Code:    u.ErrorCode,
+				Message: u.ErrorMessage,
+			},
+		})
+	}
+}
+
+// UploadFile starts asynchronous upload of the file with the priority from 1 to 32.
+func (manager *Manager) UploadFile(inputFile client.InputFile, fileType client.FileType, priority int32) (*Upload, error) {
+	file, err := manager.tdlibClient.UploadFile(&client.UploadFileRequest{
+		File:     inputFile,
+		FileType: fileType,
+		Priority: priority,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.Track(file), nil
+}
+
+// Track starts tracking upload of the file which is being uploaded. If the file is already tracked the existing upload is returned.
+// The upload fails if the file is not being uploaded and is not uploaded.
+func (manager *Manager) Track(file *client.File) *Upload {
+	manager.mu.Lock()
+	upload, ok := manager.uploads[file.ID]
+	if !ok {
+		upload = newUpload(manager, file)
+		manager.uploads[file.ID] = upload
+	}
+	manager.mu.Unlock()
+
+	if !ok {
+		manager.update(file)
+	}
+
+	return upload
+}
+
+// SendMessage sends a message and tracks uploads of its files until the message is sent.
+func (manager *Manager) SendMessage(request *client.SendMessageRequest) (*MessageUpload, error) {
+	manager.startSending()
+	defer manager.stopSending()
+
+	message, err := manager.tdlibClient.SendMessage(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.trackMessage(message), nil
+}
+
+// SendMessageAlbum sends messages grouped together into an album and tracks uploads of their files.
+func (manager *Manager) SendMessageAlbum(request *client.SendMessageAlbumRequest) ([]*MessageUpload, error) {
+	manager.startSending()
+	defer manager.stopSending()
+
+	messages, err := manager.tdlibClient.SendMessageAlbum(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageUploads []*MessageUpload
+	for _, message := range messages.Messages {
+		messageUploads = append(messageUploads, manager.trackMessage(message))
+	}
+
+	return messageUploads, nil
+}
+
+// startSending makes the manager keep results of sending until the sent messages are tracked.
+func (manager *Manager) startSending() {
+	manager.mu.Lock()
+	manager.sending++
+	manager.mu.Unlock()
+}
+
+// stopSending drops kept results of sending when there are no more send requests waiting for TDLib response.
+// The kept results belong to messages not sent by the manager at this point.
+func (manager *Manager) stopSending() {
+	manager.mu.Lock()
+	manager.sending--
+	if manager.sending == 0 && len(manager.sent) > 0 {
+		manager.sent = map[messageKey]sentMessage{}
+	}
+	manager.mu.Unlock()
+}
+
+// trackMessage starts tracking uploads of files of the outgoing message until the message is sent.
+// Messages which are already sent are completed immediately.
+// It must be called before the send request returning the message is finished, see startSending.
+func (manager *Manager) trackMessage(message *client.Message) *MessageUpload {
+	messageUpload := &MessageUpload{
+		manager:   manager,
+		done:      make(chan struct{}),
+		ChatID:    message.ChatID,
+		MessageID: message.ID,
+	}
+
+	for _, file := range messageFiles(message.Content) {
+		messageUpload.Uploads = append(messageUpload.Uploads, manager.Track(file))
+	}
+
+	if message.SendingState == nil {
+		messageUpload.complete(message, nil)
+
+		return messageUpload
+	}
+
+	key := messageKey{message.ChatID, message.ID}
+
+	manager.mu.Lock()
+	result, ok := manager.sent[key]
+	if ok {
+		delete(manager.sent, key)
+	} else {
+		manager.messages[key] = messageUpload
+	}
+	manager.mu.Unlock()
+
+	if ok {
+		manager.completeMessage(messageUpload, result.message, result.err)
+	}
+
+	return messageUpload
+}
+
+// Cancel stops uploading the file.
+func (manager *Manager) Cancel(fileID int32) error {
+	manager.mu.Lock()
+	upload, ok := manager.uploads[fileID]
+	manager.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	// updateFile caused by the cancellation must not be reported as a failure
+	upload.cancel()
+
+	_, err := manager.tdlibClient.CancelUploadFile(&client.CancelUploadFileRequest{
+		FileID: fileID,
+	})
+
+	manager.finish(upload, ErrCancelled)
+
+	return err
+}
+
+// Throughput returns upload speed of all tracked files in bytes per second.
+func (manager *Manager) Throughput() float64 {
+	return manager.meter.rate()
+}
+
+func (manager *Manager) update(file *client.File) {
+	manager.mu.Lock()
+	upload, ok := manager.uploads[file.ID]
+	manager.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	manager.meter.add(int64(upload.update(file)))
+
+	switch {
+	case file.Remote != nil && file.Remote.IsUploadingCompleted:
+		manager.finish(upload, nil)
+
+	case upload.isFailed():
+		manager.finish(upload, ErrFailed)
+	}
+}
+
+func (manager *Manager) messageSent(message *client.Message, oldMessageID int64, err error) {
+	key := messageKey{message.ChatID, oldMessageID}
+
+	manager.mu.Lock()
+	messageUpload, ok := manager.messages[key]
+	if ok {
+		delete(manager.messages, key)
+	} else if manager.sending > 0 {
+		// the message may be sent by a request which is still waiting for TDLib response
+		manager.sent[key] = sentMessage{
+			message: message,
+			err:     err,
+		}
+	}
+	manager.mu.Unlock()
+
+	if ok {
+		manager.completeMessage(messageUpload, message, err)
+	}
+}
+
+// completeMessage completes the message upload with the result of sending.
+func (manager *Manager) completeMessage(messageUpload *MessageUpload, message *client.Message, err error) {
+	if err != nil {
+		for _, upload := range messageUpload.Uploads {
+			manager.finish(upload, err)
+		}
+	}
+
+	messageUpload.complete(message, err)
+}
+
+func (manager *Manager) finish(upload *Upload, err error) {
+	manager.mu.Lock()
+	if manager.uploads[upload.FileID] != upload {
+		manager.mu.Unlock()
+
+		return
+	}
+	delete(manager.uploads, upload.FileID)
+	manager.mu.Unlock()
+
+	upload.complete(err)
+}
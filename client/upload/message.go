@@ -0,0 +1,152 @@
+package upload
+
+import (
+	"context"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// MessageUpload tracks uploads of all files of an outgoing message.
+type MessageUpload struct {
+	manager *Manager
+	mu      sync.Mutex
+	done    chan struct{}
+	message *client.Message
+	err     error
+	// Uploads of the message files
+	Uploads []*Upload
+	// Identifier of the chat
+	ChatID int64
+	// Temporary identifier of the message
+	MessageID int64
+}
+
+// Progress returns aggregate progress of all files of the message.
+func (messageUpload *MessageUpload) Progress() Progress {
+	var progress Progress
+	progress.IsCompleted = true
+
+	for _, upload := range messageUpload.Uploads {
+		last := upload.LastProgress()
+		progress.UploadedSize += last.UploadedSize
+		progress.TotalSize += last.TotalSize
+		progress.IsCompleted = progress.IsCompleted && last.IsCompleted
+	}
+
+	return progress
+}
+
+// Done returns channel which is closed when the message is sent or fails to send.
+func (messageUpload *MessageUpload) Done() <-chan struct{} {
+	return messageUpload.done
+}
+
+// Wait waits for the message to be sent and returns the sent message with its final identifier.
+// Uploads are cancelled if the context is done before the message is sent.
+func (messageUpload *MessageUpload) Wait(ctx context.Context) (*client.Message, error) {
+	select {
+	case <-messageUpload.done:
+		messageUpload.mu.Lock()
+		defer messageUpload.mu.Unlock()
+
+		return messageUpload.message, messageUpload.err
+
+	case <-ctx.Done():
+		err := messageUpload.Cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel stops uploading all files of the message.
+func (messageUpload *MessageUpload) Cancel() error {
+	var firstErr error
+	for _, upload := range messageUpload.Uploads {
+		err := upload.Cancel()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	manager := messageUpload.manager
+	manager.mu.Lock()
+	delete(manager.messages, messageKey{messageUpload.ChatID, messageUpload.MessageID})
+	manager.mu.Unlock()
+
+	messageUpload.complete(nil, ErrCancelled)
+
+	return firstErr
+}
+
+func (messageUpload *MessageUpload) complete(message *client.Message, err error) {
+	messageUpload.mu.Lock()
+	defer messageUpload.mu.Unlock()
+
+	select {
+	case <-messageUpload.done:
+		return
+	default:
+	}
+
+	messageUpload.message = message
+	messageUpload.err = err
+	close(messageUpload.done)
+}
+
+// messageFiles returns files of the message content which are not uploaded yet.
+func messageFiles(content client.MessageContent) []*client.File {
+	var files []*client.File
+
+	switch c := content.(type) {
+	case *client.MessagePhoto:
+		if c.Photo != nil {
+			for _, size := range c.Photo.Sizes {
+				files = append(files, size.Photo)
+			}
+		}
+	case *client.MessageDocument:
+		if c.Document != nil {
+			files = append(files, c.Document.Document)
+		}
+	case *client.MessageVideo:
+		if c.Video != nil {
+			files = append(files, c.Video.Video)
+		}
+	case *client.MessageAudio:
+		if c.Audio != nil {
+			files = append(files, c.Audio.Audio)
+		}
+	case *client.MessageAnimation:
+		if c.Animation != nil {
+			files = append(files, c.Animation.Animation)
+		}
+	case *client.MessageVoiceNote:
+		if c.VoiceNote != nil {
+			files = append(files, c.VoiceNote.Voice)
+		}
+	case *client.MessageVideoNote:
+		if c.VideoNote != nil {
+			files = append(files, c.VideoNote.Video)
+		}
+	case *client.MessageSticker:
+		if c.Sticker != nil {
+			files = append(files, c.Sticker.Sticker)
+		}
+	}
+
+	var pending []*client.File
+	seen := map[int32]bool{}
+	for _, file := range files {
+		if file == nil || seen[file.ID] || (file.Remote != nil && file.Remote.IsUploadingCompleted) {
+			continue
+		}
+		seen[file.ID] = true
+		pending = append(pending, file)
+	}
+
+	return pending
+}
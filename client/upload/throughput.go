@@ -0,0 +1,168 @@
+package upload
+
+import (
+	"sync"
+	"time"
+)
+
+type sample struct {
+	at    time.Time
+	bytes int64
+}
+
+// meter measures throughput over a sliding time window.
+type meter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	samples []sample
+}
+
+func newMeter(window time.Duration) *meter {
+	return &meter{
+		window: window,
+	}
+}
+
+func (meter *meter) add(bytes int64) {
+	if bytes <= 0 {
+		return
+	}
+
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+
+	now := time.Now()
+	meter.samples = append(meter.samples, sample{at: now, bytes: bytes})
+	meter.trim(now)
+}
+
+// rate returns number of bytes per second during the window.
+func (meter *meter) rate() float64 {
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+
+	meter.trim(time.Now())
+
+	var total int64
+	for _, sample := range meter.samples {
+		total += sample.bytes
+	}
+
+	return float64(total) / meter.window.Seconds()
+}
+
+func (meter *meter) trim(now time.Time) {
+	i := 0
+	for i < len(meter.samples) && now.Sub(meter.samples[i].at) > meter.window {
+		i++
+	}
+	meter.samples = meter.samples[i:]
+}
+
+// BatchProgress contains aggregate state of uploads of a batch.
+type BatchProgress struct {
+	// Number of files in the batch
+	Files int
+	// Number of fully uploaded files
+	Completed int
+	// Total uploaded bytes
+	UploadedSize int64
+	// Total size of the files; 0 if unknown
+	TotalSize int64
+	// Upload speed of the batch in bytes per second
+	Throughput float64
+}
+
+// Percent returns upload progress of the batch in percents; 0 if the size of the files is unknown.
+func (progress BatchProgress) Percent() float64 {
+	if progress.Files > 0 && progress.Completed == progress.Files {
+		return 100
+	}
+
+	if progress.TotalSize <= 0 {
+		return 0
+	}
+
+	return float64(progress.UploadedSize) * 100 / float64(progress.TotalSize)
+}
+
+// Batch aggregates progress and throughput of several uploads.
+type Batch struct {
+	mu      sync.Mutex
+	meter   *meter
+	uploads []*Upload
+	sizes   map[int32]int32
+}
+
+// NewBatch creates new batch of uploads. Throughput is measured over the manager's throughput window.
+func (manager *Manager) NewBatch(uploads ...*Upload) *Batch {
+	batch := &Batch{
+		meter: newMeter(manager.window),
+		sizes: map[int32]int32{},
+	}
+
+	for _, upload := range uploads {
+		batch.Add(upload)
+	}
+
+	return batch
+}
+
+// Add adds the upload to the batch.
+func (batch *Batch) Add(upload *Upload) {
+	batch.mu.Lock()
+	batch.uploads = append(batch.uploads, upload)
+	batch.sizes[upload.FileID] = upload.LastProgress().UploadedSize
+	batch.mu.Unlock()
+
+	upload.OnProgress(func(progress Progress) {
+		batch.mu.Lock()
+		delta := progress.UploadedSize - batch.sizes[progress.FileID]
+		batch.sizes[progress.FileID] = progress.UploadedSize
+		batch.mu.Unlock()
+
+		batch.meter.add(int64(delta))
+	})
+}
+
+// Progress returns aggregate progress of the batch.
+func (batch *Batch) Progress() BatchProgress {
+	batch.mu.Lock()
+	uploads := make([]*Upload, len(batch.uploads))
+	copy(uploads, batch.uploads)
+	batch.mu.Unlock()
+
+	progress := BatchProgress{
+		Files:      len(uploads),
+		Throughput: batch.meter.rate(),
+	}
+
+	for _, upload := range uploads {
+		last := upload.LastProgress()
+		progress.UploadedSize += int64(last.UploadedSize)
+		progress.TotalSize += int64(last.TotalSize)
+		if last.IsCompleted {
+			progress.Completed++
+		}
+	}
+
+	return progress
+}
+
+// Done returns channel which is closed when all uploads of the batch finish.
+func (batch *Batch) Done() <-chan struct{} {
+	batch.mu.Lock()
+	uploads := make([]*Upload, len(batch.uploads))
+	copy(uploads, batch.uploads)
+	batch.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, upload := range uploads {
+			<-upload.Done()
+		}
+		close(done)
+	}()
+
+	return done
+}
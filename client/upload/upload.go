@@ -0,0 +1,207 @@
+package upload
+
+import (
+	"context"
+	"sync"
+
+	"github.com/u-robot/go-tdlib/client"
+)
+
+// Progress contains state of a file upload.
+type Progress struct {
+	// Identifier of the file
+	FileID int32
+	// Size of the remote available part of the file
+	UploadedSize int32
+	// Size of the file; expected size if the exact size is unknown; 0 if unknown
+	TotalSize int32
+	// True, if the file is fully uploaded
+	IsCompleted bool
+}
+
+// Percent returns upload progress in percents; 0 if the size of the file is unknown.
+func (progress Progress) Percent() float64 {
+	if progress.IsCompleted {
+		return 100
+	}
+
+	if progress.TotalSize <= 0 {
+		return 0
+	}
+
+	return float64(progress.UploadedSize) * 100 / float64(progress.TotalSize)
+}
+
+func newProgress(file *client.File) Progress {
+	total := file.Size
+	if total == 0 {
+		total = file.ExpectedSize
+	}
+
+	progress := Progress{
+		FileID:    file.ID,
+		TotalSize: total,
+	}
+
+	if file.Remote != nil {
+		progress.UploadedSize = file.Remote.UploadedSize
+		progress.IsCompleted = file.Remote.IsUploadingCompleted
+	}
+
+	return progress
+}
+
+// Upload tracks upload of a file.
+type Upload struct {
+	manager   *Manager
+	mu        sync.Mutex
+	progress  chan Progress
+	done      chan struct{}
+	callbacks []func(Progress)
+	last      Progress
+	file      *client.File
+	err       error
+	cancelled bool
+	// Identifier of the file
+	FileID int32
+}
+
+func newUpload(manager *Manager, file *client.File) *Upload {
+	return &Upload{
+		manager:  manager,
+		progress: make(chan Progress, 1),
+		done:     make(chan struct{}),
+		last:     newProgress(file),
+		file:     file,
+		FileID:   file.ID,
+	}
+}
+
+// Progress returns channel receiving the latest progress of the upload. Intermediate values are dropped
+// if the channel is not read in time. The channel is closed when the upload finishes.
+func (upload *Upload) Progress() <-chan Progress {
+	return upload.progress
+}
+
+// LastProgress returns the latest known progress of the upload.
+func (upload *Upload) LastProgress() Progress {
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	return upload.last
+}
+
+// OnProgress registers callback called on every progress change of the upload.
+func (upload *Upload) OnProgress(callback func(Progress)) {
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	upload.callbacks = append(upload.callbacks, callback)
+}
+
+// Done returns channel which is closed when the upload finishes.
+func (upload *Upload) Done() <-chan struct{} {
+	return upload.done
+}
+
+// Result returns the uploaded file or error. It must be called after the upload finishes.
+func (upload *Upload) Result() (*client.File, error) {
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	return upload.file, upload.err
+}
+
+// Wait waits for the upload to finish and returns the uploaded file.
+// The upload is cancelled if the context is done before it finishes.
+func (upload *Upload) Wait(ctx context.Context) (*client.File, error) {
+	select {
+	case <-upload.done:
+		return upload.Result()
+
+	case <-ctx.Done():
+		err := upload.Cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel stops uploading the file.
+func (upload *Upload) Cancel() error {
+	return upload.manager.Cancel(upload.FileID)
+}
+
+// update applies new state of the file and returns number of bytes uploaded since the previous state.
+// Callbacks are called without the lock, so they can use the upload.
+func (upload *Upload) update(file *client.File) int32 {
+	upload.mu.Lock()
+
+	select {
+	case <-upload.done:
+		upload.mu.Unlock()
+
+		return 0
+	default:
+	}
+
+	progress := newProgress(file)
+	delta := progress.UploadedSize - upload.last.UploadedSize
+	if delta < 0 {
+		delta = 0
+	}
+
+	upload.last = progress
+	upload.file = file
+
+	callbacks := make([]func(Progress), len(upload.callbacks))
+	copy(callbacks, upload.callbacks)
+
+	select {
+	case <-upload.progress:
+	default:
+	}
+	upload.progress <- progress
+	upload.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(progress)
+	}
+
+	return delta
+}
+
+// isFailed returns true if the upload is stopped before completion and it is not cancelled.
+// Uploads are tracked after they are started, so the initial state of the file is already uploading.
+func (upload *Upload) isFailed() bool {
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	remote := upload.file.Remote
+
+	return !upload.cancelled && remote != nil && !remote.IsUploadingActive && !remote.IsUploadingCompleted
+}
+
+// cancel marks the upload as cancelled, so stopping of the upload is not reported as a failure.
+func (upload *Upload) cancel() {
+	upload.mu.Lock()
+	upload.cancelled = true
+	upload.mu.Unlock()
+}
+
+func (upload *Upload) complete(err error) {
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	select {
+	case <-upload.done:
+		return
+	default:
+	}
+
+	upload.err = err
+	close(upload.progress)
+	close(upload.done)
+}